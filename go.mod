@@ -3,7 +3,7 @@ module github.com/m-mizutani/urlscan-go
 go 1.14
 
 require (
-	github.com/pkg/errors v0.8.1
+	github.com/pkg/errors v0.9.1
 	github.com/sirupsen/logrus v1.3.0
 	github.com/stretchr/testify v1.2.2
 	golang.org/x/sys v0.0.0-20200223170610-d5e6a3e2c0ae // indirect
@@ -0,0 +1,128 @@
+package urlscan
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// maxDuration is the longest time.Duration. Delays are clamped to it not to overflow.
+const maxDuration = time.Duration(math.MaxInt64)
+
+// Backoff decides how long Await waits before each retry of result API.
+type Backoff interface {
+	// Delay returns wait time before the retry-th retry. retry starts from 1.
+	Delay(retry int) time.Duration
+}
+
+// BackoffFunc is a custom Backoff by a function.
+type BackoffFunc func(retry int) time.Duration
+
+// Delay implements Backoff.
+func (x BackoffFunc) Delay(retry int) time.Duration {
+	return x(retry)
+}
+
+// DefaultBackoff increases delay quadratically from 1.1 seconds up to 20 seconds.
+var DefaultBackoff Backoff = BackoffFunc(getExpWaitTime)
+
+func getExpWaitTime(count int) time.Duration {
+	d := (count * count * 100) + 1000
+	if d > 20*1000 {
+		d = 20 * 1000
+	}
+
+	return time.Millisecond * time.Duration(d)
+}
+
+// ConstantBackoff waits same Interval for every retry.
+type ConstantBackoff struct {
+	Interval time.Duration
+}
+
+// Delay implements Backoff.
+func (x ConstantBackoff) Delay(retry int) time.Duration {
+	return x.Interval
+}
+
+// ExponentialBackoff multiplies delay by Multiplier (default 2) for each retry from Initial up to Max.
+// Jitter (0.0 to 1.0) is a ratio of the delay to be randomized. For example, 0.5 means half of delay is fixed and another half is random.
+type ExponentialBackoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+	Jitter     float64
+}
+
+// Delay implements Backoff.
+func (x ExponentialBackoff) Delay(retry int) time.Duration {
+	multiplier := x.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+
+	max := x.Max
+	if max <= 0 {
+		max = maxDuration
+	}
+
+	d := float64(x.Initial)
+	for i := 1; i < retry && d < float64(max); i++ {
+		d *= multiplier
+	}
+	// float64(maxDuration) is rounded up to 2^63, so compare it before converting.
+	if d >= float64(max) {
+		return withJitter(max, x.Jitter)
+	}
+
+	return withJitter(time.Duration(d), x.Jitter)
+}
+
+// FibonacciBackoff waits Unit multiplied by Fibonacci number (1, 1, 2, 3, 5, ...) up to Max.
+type FibonacciBackoff struct {
+	Unit time.Duration
+	Max  time.Duration
+}
+
+// Delay implements Backoff.
+func (x FibonacciBackoff) Delay(retry int) time.Duration {
+	if x.Unit <= 0 {
+		return 0
+	}
+	max := x.Max
+	if max <= 0 {
+		max = maxDuration
+	}
+
+	a, b := time.Duration(1), time.Duration(1)
+	for i := 1; i < retry; i++ {
+		if b > max/x.Unit {
+			return max
+		}
+		a, b = b, a+b
+		if b < 0 {
+			// Overflow of the next number that is not used yet.
+			b = maxDuration
+		}
+	}
+
+	if d := a * x.Unit; d < max {
+		return d
+	}
+	return max
+}
+
+func withJitter(d time.Duration, jitter float64) time.Duration {
+	if jitter <= 0 || d <= 0 {
+		return d
+	}
+	if jitter > 1 {
+		jitter = 1
+	}
+
+	random := time.Duration(float64(d) * jitter)
+	if float64(d)*jitter >= float64(maxDuration) {
+		random = maxDuration - 1
+	}
+	return d - random + time.Duration(rand.Int63n(int64(random)+1))
+}
@@ -0,0 +1,82 @@
+package urlscan_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/urlscan-go/urlscan"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultBackoff(t *testing.T) {
+	assert.Equal(t, 1100*time.Millisecond, urlscan.DefaultBackoff.Delay(1))
+	assert.Equal(t, 1400*time.Millisecond, urlscan.DefaultBackoff.Delay(2))
+	assert.Equal(t, 20*time.Second, urlscan.DefaultBackoff.Delay(29))
+}
+
+func TestConstantBackoff(t *testing.T) {
+	b := urlscan.ConstantBackoff{Interval: 3 * time.Second}
+	assert.Equal(t, 3*time.Second, b.Delay(1))
+	assert.Equal(t, 3*time.Second, b.Delay(10))
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	b := urlscan.ExponentialBackoff{Initial: time.Second, Max: 10 * time.Second}
+	assert.Equal(t, 1*time.Second, b.Delay(1))
+	assert.Equal(t, 2*time.Second, b.Delay(2))
+	assert.Equal(t, 8*time.Second, b.Delay(4))
+	assert.Equal(t, 10*time.Second, b.Delay(5))
+	assert.Equal(t, 10*time.Second, b.Delay(100))
+
+	b.Multiplier = 3
+	assert.Equal(t, 9*time.Second, b.Delay(3))
+}
+
+func TestExponentialBackoffJitter(t *testing.T) {
+	b := urlscan.ExponentialBackoff{Initial: time.Second, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		d := b.Delay(2)
+		assert.True(t, time.Second <= d && d <= 2*time.Second, "delay: %v", d)
+	}
+}
+
+func TestFibonacciBackoff(t *testing.T) {
+	b := urlscan.FibonacciBackoff{Unit: time.Second, Max: 10 * time.Second}
+	var delays []time.Duration
+	for i := 1; i <= 7; i++ {
+		delays = append(delays, b.Delay(i))
+	}
+	assert.Equal(t, []time.Duration{
+		1 * time.Second, 1 * time.Second, 2 * time.Second, 3 * time.Second,
+		5 * time.Second, 8 * time.Second, 10 * time.Second,
+	}, delays)
+}
+
+func TestBackoffOverflow(t *testing.T) {
+	maxDuration := time.Duration(math.MaxInt64)
+
+	exp := urlscan.ExponentialBackoff{Initial: time.Second}
+	assert.Equal(t, maxDuration, exp.Delay(35))
+	assert.Equal(t, maxDuration, exp.Delay(10000))
+
+	exp.Jitter = 1
+	assert.True(t, exp.Delay(35) >= 0)
+
+	fib := urlscan.FibonacciBackoff{Unit: time.Second}
+	assert.Equal(t, maxDuration, fib.Delay(100))
+	assert.Equal(t, maxDuration, fib.Delay(10000))
+
+	fib = urlscan.FibonacciBackoff{Unit: time.Nanosecond}
+	for i := 1; i <= 200; i++ {
+		assert.True(t, fib.Delay(i) > 0, "retry: %d", i)
+	}
+	assert.Equal(t, maxDuration, fib.Delay(200))
+}
+
+func TestBackoffFunc(t *testing.T) {
+	b := urlscan.BackoffFunc(func(retry int) time.Duration {
+		return time.Duration(retry) * time.Minute
+	})
+	assert.Equal(t, 3*time.Minute, b.Delay(3))
+}
@@ -0,0 +1,183 @@
+package urlscan
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultBatchConcurrency is a number of Tasks polled at the same time by WaitAll and WaitAny.
+const DefaultBatchConcurrency = 8
+
+// BatchOptions is options of WaitAll and WaitAny.
+type BatchOptions struct {
+	// Concurrency is maximum number of Tasks polled at the same time. Default is DefaultBatchConcurrency.
+	Concurrency int
+	// RequestInterval is minimum interval between requests to result API, shared by all Tasks. Default is no limit.
+	RequestInterval time.Duration
+	// WaitOptions are applied to Await of each Task.
+	WaitOptions []WaitOption
+}
+
+// BatchResult is a result of a Task in WaitAll and WaitAny.
+type BatchResult struct {
+	// Index is position of the Task in given tasks.
+	Index  int
+	Task   *Task
+	Result WaitResult
+	// Err is an error returned by Await. It is context error if polling of the Task did not finish before ctx was done.
+	Err error
+}
+
+// throttle makes requests keep minimum interval. It is safe for concurrent use.
+type throttle struct {
+	interval time.Duration
+	mutex    sync.Mutex
+	next     time.Time
+}
+
+func newThrottle(interval time.Duration) *throttle {
+	return &throttle{interval: interval}
+}
+
+// wait blocks until next request is allowed.
+func (x *throttle) wait(ctx context.Context) error {
+	if x == nil || x.interval <= 0 {
+		return nil
+	}
+
+	x.mutex.Lock()
+	now := time.Now()
+	if x.next.Before(now) {
+		x.next = now
+	}
+	delay := x.next.Sub(now)
+	x.next = x.next.Add(x.interval)
+	x.mutex.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	return sleepContext(ctx, delay)
+}
+
+// waitThrottle makes Await wait for t before each request to result API.
+func waitThrottle(t *throttle) WaitOption {
+	return func(x *waitConfig) {
+		x.throttle = t
+	}
+}
+
+// runBatch polls tasks concurrently and sends BatchResult to ch for each Task in order of completion.
+func runBatch(ctx context.Context, tasks []*Task, opts BatchOptions, ch chan<- BatchResult) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBatchConcurrency
+	}
+	waitOpts := append(append([]WaitOption{}, opts.WaitOptions...), waitThrottle(newThrottle(opts.RequestInterval)))
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, task := range tasks {
+		wg.Add(1)
+		go func(idx int, task *Task) {
+			defer wg.Done()
+			res := BatchResult{Index: idx, Task: task}
+
+			select {
+			case sem <- struct{}{}:
+				res.Result, res.Err = task.Await(ctx, waitOpts...)
+				<-sem
+			case <-ctx.Done():
+				res.Err = errors.Wrap(ctx.Err(), "Interrupted before polling")
+			}
+			ch <- res
+		}(i, task)
+	}
+
+	wg.Wait()
+	close(ch)
+}
+
+// WaitAll polls all tasks concurrently until all of them finish or ctx is done, and returns results in the same order with tasks.
+// When ctx is done, results of unfinished Tasks have context error in Err and the error is also returned.
+// Result of each scan is stored into Task.Result.
+func WaitAll(ctx context.Context, tasks []*Task, opts BatchOptions) ([]BatchResult, error) {
+	ch := make(chan BatchResult, len(tasks))
+	go runBatch(ctx, tasks, opts, ch)
+
+	results := make([]BatchResult, len(tasks))
+	for res := range ch {
+		results[res.Index] = res
+	}
+
+	return results, interrupted(ctx, results)
+}
+
+// interrupted returns ctx.Err() if polling of some Task was interrupted by ctx. It returns nil if all Tasks finished
+// even if ctx is done after that.
+func interrupted(ctx context.Context, results []BatchResult) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	for _, res := range results {
+		if isContextError(res.Err) {
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// ErrNoTaskDone is returned (wrapped) by WaitAny when no Task is done.
+var ErrNoTaskDone = errors.New("No task is done")
+
+// noTaskDoneError is ErrNoTaskDone with the last failure. errors.Is matches ErrNoTaskDone and errors.As can retrieve
+// the last failure such as *APIError.
+type noTaskDoneError struct {
+	last error
+}
+
+func (x *noTaskDoneError) Error() string {
+	return fmt.Sprintf("%v, last error: %v", ErrNoTaskDone, x.last)
+}
+
+func (x *noTaskDoneError) Is(target error) bool {
+	return target == ErrNoTaskDone
+}
+
+func (x *noTaskDoneError) Unwrap() error {
+	return x.last
+}
+
+// WaitAny polls tasks concurrently and returns the first Task that is done successfully. Polling of other Tasks is canceled.
+// If no Task is done, it returns an error that matches ErrNoTaskDone by errors.Is and wraps the last failure.
+func WaitAny(ctx context.Context, tasks []*Task, opts BatchOptions) (BatchResult, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ch := make(chan BatchResult, len(tasks))
+	go runBatch(ctx, tasks, opts, ch)
+
+	var last BatchResult
+	found := false
+	for res := range ch {
+		if !found && res.Err == nil {
+			last, found = res, true
+			cancel()
+		} else if !found {
+			last = res
+		}
+	}
+
+	if found {
+		return last, nil
+	}
+	if last.Err != nil {
+		return last, &noTaskDoneError{last: last.Err}
+	}
+	return last, ErrNoTaskDone
+}
@@ -0,0 +1,181 @@
+package urlscan_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/urlscan-go/urlscan"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBatchClient returns a client of which result API responds according to prefix of uuid:
+// "done-" is done, "pending-" is never finished and "deleted-" is deleted. Submission is accepted with uuid "done-1".
+func newBatchClient(t *testing.T, active, maxActive *int32) urlscan.Client {
+	return newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			w.Write([]byte(`{"uuid":"done-1","result":"https://urlscan.io/result/done-1/","visibility":"public"}`))
+			return
+		}
+
+		n := atomic.AddInt32(active, 1)
+		defer atomic.AddInt32(active, -1)
+		for {
+			m := atomic.LoadInt32(maxActive)
+			if n <= m || atomic.CompareAndSwapInt32(maxActive, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		uuid := strings.Trim(strings.TrimPrefix(r.URL.Path, "/result/"), "/")
+		switch {
+		case strings.HasPrefix(uuid, "done-"):
+			w.Write([]byte(`{"task":{"uuid":"` + uuid + `"}}`))
+		case strings.HasPrefix(uuid, "deleted-"):
+			w.WriteHeader(http.StatusGone)
+			w.Write([]byte(`{"message":"Scan deleted","status":410}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Scan is not finished yet","status":404}`))
+		}
+	}))
+}
+
+var fastWait = []urlscan.WaitOption{
+	urlscan.WaitBackoff(urlscan.ConstantBackoff{Interval: 10 * time.Millisecond}),
+}
+
+func TestWaitAll(t *testing.T) {
+	var active, maxActive int32
+	client := newBatchClient(t, &active, &maxActive)
+
+	var tasks []*urlscan.Task
+	for i := 0; i < 10; i++ {
+		task := client.ResultTask(fmt.Sprintf("done-%d", i))
+		tasks = append(tasks, &task)
+	}
+	deleted := client.ResultTask("deleted-0")
+	tasks = append(tasks, &deleted)
+
+	results, err := urlscan.WaitAll(context.Background(), tasks, urlscan.BatchOptions{
+		Concurrency: 3,
+		WaitOptions: fastWait,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 11, len(results))
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, i, results[i].Index)
+		assert.NoError(t, results[i].Err)
+		assert.Equal(t, urlscan.TaskStatusDone, results[i].Result.Status)
+		assert.Equal(t, fmt.Sprintf("done-%d", i), tasks[i].Result.Task.UUID)
+	}
+	assert.Error(t, results[10].Err)
+	assert.Equal(t, urlscan.TaskStatusDeleted, results[10].Result.Status)
+	assert.True(t, atomic.LoadInt32(&maxActive) <= 3)
+}
+
+func TestWaitAllPartial(t *testing.T) {
+	var active, maxActive int32
+	client := newBatchClient(t, &active, &maxActive)
+
+	done := client.ResultTask("done-0")
+	pending := client.ResultTask("pending-0")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	results, err := urlscan.WaitAll(ctx, []*urlscan.Task{&done, &pending}, urlscan.BatchOptions{WaitOptions: fastWait})
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	require.Equal(t, 2, len(results))
+	assert.NoError(t, results[0].Err)
+	assert.Error(t, results[1].Err)
+	assert.Equal(t, urlscan.TaskStatusPending, results[1].Result.Status)
+}
+
+func TestWaitAllRequestInterval(t *testing.T) {
+	var active, maxActive int32
+	client := newBatchClient(t, &active, &maxActive)
+
+	var tasks []*urlscan.Task
+	for i := 0; i < 5; i++ {
+		task := client.ResultTask(fmt.Sprintf("done-%d", i))
+		tasks = append(tasks, &task)
+	}
+
+	start := time.Now()
+	_, err := urlscan.WaitAll(context.Background(), tasks, urlscan.BatchOptions{
+		Concurrency:     5,
+		RequestInterval: 50 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	assert.True(t, time.Since(start) >= 200*time.Millisecond)
+}
+
+func TestWaitAny(t *testing.T) {
+	var active, maxActive int32
+	client := newBatchClient(t, &active, &maxActive)
+
+	pending := client.ResultTask("pending-0")
+	deleted := client.ResultTask("deleted-0")
+	done := client.ResultTask("done-0")
+
+	res, err := urlscan.WaitAny(context.Background(), []*urlscan.Task{&pending, &deleted, &done}, urlscan.BatchOptions{WaitOptions: fastWait})
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Index)
+	assert.Equal(t, "done-0", res.Task.Result.Task.UUID)
+}
+
+func TestWaitAnyNoTaskDone(t *testing.T) {
+	var active, maxActive int32
+	client := newBatchClient(t, &active, &maxActive)
+
+	deleted := client.ResultTask("deleted-0")
+	pending := client.ResultTask("pending-0")
+
+	opts := append([]urlscan.WaitOption{urlscan.WaitMaxRetry(2)}, fastWait...)
+	_, err := urlscan.WaitAny(context.Background(), []*urlscan.Task{&deleted, &pending}, urlscan.BatchOptions{WaitOptions: opts})
+	assert.True(t, errors.Is(err, urlscan.ErrNoTaskDone))
+}
+
+func TestWaitAnyNoTaskDoneKeepsLastError(t *testing.T) {
+	var active, maxActive int32
+	client := newBatchClient(t, &active, &maxActive)
+
+	deleted := client.ResultTask("deleted-0")
+
+	_, err := urlscan.WaitAny(context.Background(), []*urlscan.Task{&deleted}, urlscan.BatchOptions{WaitOptions: fastWait})
+	assert.True(t, errors.Is(err, urlscan.ErrNoTaskDone))
+
+	var apiErr *urlscan.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, 410, apiErr.StatusCode)
+}
+
+func TestWaitAllCanceledAfterAllDone(t *testing.T) {
+	var active, maxActive int32
+	client := newBatchClient(t, &active, &maxActive)
+	done := client.ResultTask("done-0")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Cancel ctx right after the last Task finished.
+	opts := append([]urlscan.WaitOption{urlscan.WaitOnPoll(func(ev urlscan.PollEvent) {
+		if ev.Final {
+			cancel()
+		}
+	})}, fastWait...)
+
+	results, err := urlscan.WaitAll(ctx, []*urlscan.Task{&done}, urlscan.BatchOptions{WaitOptions: opts})
+	require.NoError(t, err)
+	assert.NoError(t, results[0].Err)
+	assert.Error(t, ctx.Err())
+}
@@ -0,0 +1,327 @@
+package urlscan
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultBulkConcurrency is a number of concurrent submissions of BulkSubmitter.
+const DefaultBulkConcurrency = 4
+
+// DefaultBulkMaxRateLimitRetry is a number of retries of a submission that got 429 response.
+const DefaultBulkMaxRateLimitRetry = 5
+
+// BulkResult is a result of a submission by BulkSubmitter.
+type BulkResult struct {
+	// Index is sequence number of the SubmitArguments in input stream, starting from 0.
+	Index int
+	Args  SubmitArguments
+	// Task is set if the submission succeeded.
+	Task *Task
+	// Err is set if the submission failed after retries.
+	Err error
+	// Attempts is a number of requests to submit the URL.
+	Attempts int
+}
+
+// quotaWindow is a client side quota such as 60 scans per minute.
+type quotaWindow struct {
+	window time.Duration
+	limit  int
+	times  []time.Time
+}
+
+// quota limits number of submissions in sliding windows. It is safe for concurrent use.
+type quota struct {
+	mutex   sync.Mutex
+	windows []*quotaWindow
+}
+
+// reserve records a submission and returns 0 if all windows have room. Otherwise it returns how long to wait.
+func (x *quota) reserve(now time.Time) time.Duration {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	var delay time.Duration
+	for _, w := range x.windows {
+		for len(w.times) > 0 && now.Sub(w.times[0]) >= w.window {
+			w.times = w.times[1:]
+		}
+		if len(w.times) >= w.limit {
+			if d := w.times[0].Add(w.window).Sub(now); d > delay {
+				delay = d
+			}
+		}
+	}
+
+	if delay > 0 {
+		return delay
+	}
+	for _, w := range x.windows {
+		w.times = append(w.times, now)
+	}
+	return 0
+}
+
+func (x *quota) wait(ctx context.Context) error {
+	for {
+		delay := x.reserve(time.Now())
+		if delay == 0 {
+			return nil
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// BulkSubmitter submits many URLs concurrently with client side quota, server side rate limit and retry.
+type BulkSubmitter struct {
+	client            *Client
+	concurrency       int
+	quota             quota
+	retryPolicy       RetryPolicy
+	maxRateLimitRetry int
+}
+
+// BulkOption is an option of NewBulkSubmitter.
+type BulkOption func(*BulkSubmitter)
+
+// BulkConcurrency sets number of concurrent submissions. Default is DefaultBulkConcurrency.
+func BulkConcurrency(n int) BulkOption {
+	return func(x *BulkSubmitter) {
+		x.concurrency = n
+	}
+}
+
+// BulkQuota limits number of submissions to limit per window, e.g. BulkQuota(time.Minute, 60).
+// It can be given multiple times for minute, hour and day quota of your account. limit of 0 or less is ignored.
+func BulkQuota(window time.Duration, limit int) BulkOption {
+	return func(x *BulkSubmitter) {
+		if limit <= 0 {
+			return
+		}
+		x.quota.windows = append(x.quota.windows, &quotaWindow{window: window, limit: limit})
+	}
+}
+
+// BulkRetryPolicy sets RetryPolicy for transient failures except 429 response. Default is ExponentialRetryPolicy
+// that retries a submission only if connection could not be established.
+func BulkRetryPolicy(policy RetryPolicy) BulkOption {
+	return func(x *BulkSubmitter) {
+		x.retryPolicy = policy
+	}
+}
+
+// BulkMaxRateLimitRetry sets number of retries of a submission that got 429 response. Default is DefaultBulkMaxRateLimitRetry.
+func BulkMaxRateLimitRetry(n int) BulkOption {
+	return func(x *BulkSubmitter) {
+		x.maxRateLimitRetry = n
+	}
+}
+
+// NewBulkSubmitter is a constructor of BulkSubmitter.
+func NewBulkSubmitter(client *Client, opts ...BulkOption) *BulkSubmitter {
+	submitter := &BulkSubmitter{
+		client:            client,
+		concurrency:       DefaultBulkConcurrency,
+		retryPolicy:       ExponentialRetryPolicy{},
+		maxRateLimitRetry: DefaultBulkMaxRateLimitRetry,
+	}
+
+	for _, opt := range opts {
+		opt(submitter)
+	}
+
+	return submitter
+}
+
+// Run submits SubmitArguments received from in until in is closed or ctx is done, and returns a channel of BulkResult.
+// Results are sent as soon as each submission finishes, not in input order. The returned channel is closed after all
+// submissions finished. The caller must receive results until the channel is closed or cancel ctx.
+// When ctx is done, inputs already received from in and inputs remaining in buffer of in are reported with ctx.Err().
+func (x *BulkSubmitter) Run(ctx context.Context, in <-chan SubmitArguments) <-chan BulkResult {
+	next := func() (SubmitArguments, bool) {
+		select {
+		case args, ok := <-in:
+			return args, ok
+		case <-ctx.Done():
+			return SubmitArguments{}, false
+		}
+	}
+	drain := func(report func(SubmitArguments)) {
+		for {
+			select {
+			case args, ok := <-in:
+				if !ok {
+					return
+				}
+				report(args)
+			default:
+				return
+			}
+		}
+	}
+
+	return x.run(ctx, next, drain, cap(in))
+}
+
+// RunFunc is same with Run, but SubmitArguments are given by an iterator function. next returns false when no more argument.
+// next is not called after ctx is done.
+func (x *BulkSubmitter) RunFunc(ctx context.Context, next func() (SubmitArguments, bool)) <-chan BulkResult {
+	return x.run(ctx, next, func(func(SubmitArguments)) {}, 0)
+}
+
+// run dispatches arguments given by next to workers. next must return false when ctx is done. drain reports
+// arguments buffered in input after ctx is done. buffered is maximum number of them.
+func (x *BulkSubmitter) run(ctx context.Context, next func() (SubmitArguments, bool), drain func(func(SubmitArguments)), buffered int) <-chan BulkResult {
+	concurrency := x.concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBulkConcurrency
+	}
+
+	// Results of all inputs in flight when ctx is done can be sent without receiver, not to block workers
+	// if the caller stopped receiving after cancel.
+	out := make(chan BulkResult, concurrency+buffered+1)
+
+	type job struct {
+		index int
+		args  SubmitArguments
+	}
+	jobs := make(chan job)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+
+		index := 0
+		cancelRemaining := func() {
+			drain(func(args SubmitArguments) {
+				sendBulkResult(ctx, out, BulkResult{Index: index, Args: args, Err: ctx.Err()})
+				index++
+			})
+		}
+
+		for ; ; index++ {
+			if ctx.Err() != nil {
+				cancelRemaining()
+				return
+			}
+			args, ok := next()
+			if !ok {
+				if ctx.Err() != nil {
+					cancelRemaining()
+				}
+				return
+			}
+
+			select {
+			case jobs <- job{index: index, args: args}:
+			case <-ctx.Done():
+				sendBulkResult(ctx, out, BulkResult{Index: index, Args: args, Err: ctx.Err()})
+				index++
+				cancelRemaining()
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				sendBulkResult(ctx, out, x.submit(ctx, j.index, j.args))
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// sendBulkResult sends res to out. After ctx is done, res is dropped if out is full because the caller may stop receiving.
+func sendBulkResult(ctx context.Context, out chan<- BulkResult, res BulkResult) {
+	select {
+	case out <- res:
+	case <-ctx.Done():
+		select {
+		case out <- res:
+		default:
+		}
+	}
+}
+
+// scanBudgetDelay returns how long to wait until scan budget of args reported by urlscan.io is reset.
+func (x *BulkSubmitter) scanBudgetDelay(args SubmitArguments) time.Duration {
+	return x.client.limiter().resetDelay(args.rateTarget())
+}
+
+func (x *BulkSubmitter) submit(ctx context.Context, index int, args SubmitArguments) BulkResult {
+	res := BulkResult{Index: index, Args: args}
+	var rateLimitRetry int
+
+	// Retries are controlled by BulkSubmitter, not by the Client. Otherwise a submission is retried in both layers.
+	client := *x.client
+	client.retryPolicy = nil
+	client.noRateLimitRetry = true
+
+	for {
+		if err := x.quota.wait(ctx); err != nil {
+			res.Err = errors.Wrap(err, "Interrupted while waiting quota")
+			return res
+		}
+		if d := x.scanBudgetDelay(args); d > 0 {
+			if err := sleepContext(ctx, d); err != nil {
+				res.Err = errors.Wrap(err, "Interrupted while waiting rate limit reset")
+				return res
+			}
+		}
+
+		task, err := client.SubmitContext(ctx, args)
+		res.Attempts++
+		if err == nil {
+			// Bind the Task to the original Client to wait result with retry settings of the caller.
+			task.client = x.client
+			res.Task = &task
+			res.Err = nil
+			return res
+		}
+		res.Err = err
+
+		var delay time.Duration
+		var retry bool
+		if apiErr, ok := asAPIError(err); ok && IsRateLimited(err) {
+			delay, retry = retryDelay(apiErr.RateLimit), rateLimitRetry < x.maxRateLimitRetry
+			rateLimitRetry++
+		} else if x.retryPolicy != nil && ctx.Err() == nil {
+			attempt := RetryAttempt{
+				Method:  "POST",
+				Attempt: res.Attempts - rateLimitRetry,
+				Err:     err,
+			}
+			if ok {
+				attempt.StatusCode = apiErr.StatusCode
+				attempt.URL = apiErr.URL
+			}
+			delay, retry = x.retryPolicy.Retry(attempt)
+		}
+
+		if !retry {
+			return res
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			res.Err = errors.Wrap(err, "Interrupted while waiting retry")
+			return res
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package urlscan_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/urlscan-go/urlscan"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBulkServer(t *testing.T, handler func(n int32, args map[string]interface{}, w http.ResponseWriter), opts ...urlscan.Option) *urlscan.Client {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var args map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+			t.Error(err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		handler(atomic.AddInt32(&count, 1), args, w)
+	}))
+	t.Cleanup(server.Close)
+
+	client := urlscan.NewClient("test-api-key", append([]urlscan.Option{urlscan.WithBaseURL(server.URL)}, opts...)...)
+	return &client
+}
+
+func submitOK(args map[string]interface{}, w http.ResponseWriter) {
+	w.Write([]byte(`{"uuid":"uuid-of-` + args["url"].(string) + `"}`))
+}
+
+func collectBulkResults(ch <-chan urlscan.BulkResult) []urlscan.BulkResult {
+	var results []urlscan.BulkResult
+	for res := range ch {
+		results = append(results, res)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Index < results[j].Index })
+	return results
+}
+
+func TestBulkSubmitterRun(t *testing.T) {
+	client := newBulkServer(t, func(n int32, args map[string]interface{}, w http.ResponseWriter) {
+		if args["url"] == "https://blocked.example.com" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"message":"Scan prevented","description":"Domain is blocked from scanning","status":400}`))
+			return
+		}
+		submitOK(args, w)
+	})
+
+	in := make(chan urlscan.SubmitArguments)
+	go func() {
+		defer close(in)
+		for i := 0; i < 10; i++ {
+			in <- urlscan.SubmitArguments{URL: fmt.Sprintf("https://%d.example.com", i)}
+		}
+		in <- urlscan.SubmitArguments{URL: "https://blocked.example.com"}
+	}()
+
+	submitter := urlscan.NewBulkSubmitter(client, urlscan.BulkConcurrency(3))
+	results := collectBulkResults(submitter.Run(context.Background(), in))
+
+	require.Equal(t, 11, len(results))
+	for i := 0; i < 10; i++ {
+		require.NoError(t, results[i].Err)
+		assert.Equal(t, "uuid-of-"+results[i].Args.URL, results[i].Task.UUID())
+		assert.Equal(t, 1, results[i].Attempts)
+	}
+	assert.True(t, urlscan.IsBlockedURL(results[10].Err))
+	assert.Nil(t, results[10].Task)
+}
+
+func TestBulkSubmitterRunFunc(t *testing.T) {
+	client := newBulkServer(t, func(n int32, args map[string]interface{}, w http.ResponseWriter) {
+		submitOK(args, w)
+	})
+
+	i := 0
+	next := func() (urlscan.SubmitArguments, bool) {
+		if i >= 3 {
+			return urlscan.SubmitArguments{}, false
+		}
+		i++
+		return urlscan.SubmitArguments{URL: fmt.Sprintf("https://%d.example.com", i)}, true
+	}
+
+	results := collectBulkResults(urlscan.NewBulkSubmitter(client).RunFunc(context.Background(), next))
+	require.Equal(t, 3, len(results))
+	assert.Equal(t, "https://1.example.com", results[0].Args.URL)
+}
+
+func TestBulkSubmitterRetryRateLimited(t *testing.T) {
+	client := newBulkServer(t, func(n int32, args map[string]interface{}, w http.ResponseWriter) {
+		if n == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.Header().Set("X-Rate-Limit-Reset-After", "0.05")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		submitOK(args, w)
+	})
+
+	results := collectBulkResults(urlscan.NewBulkSubmitter(client).RunFunc(context.Background(), sliceIter("https://example.com")))
+	require.Equal(t, 1, len(results))
+	require.NoError(t, results[0].Err)
+	assert.Equal(t, 2, results[0].Attempts)
+}
+
+func TestBulkSubmitterRetryPolicy(t *testing.T) {
+	client := newBulkServer(t, func(n int32, args map[string]interface{}, w http.ResponseWriter) {
+		if n <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		submitOK(args, w)
+	})
+
+	policy := urlscan.ExponentialRetryPolicy{
+		MaxAttempts:        3,
+		BaseDelay:          time.Millisecond,
+		RetryNonIdempotent: true,
+	}
+	submitter := urlscan.NewBulkSubmitter(client, urlscan.BulkRetryPolicy(policy))
+	results := collectBulkResults(submitter.RunFunc(context.Background(), sliceIter("https://example.com")))
+	require.Equal(t, 1, len(results))
+	require.NoError(t, results[0].Err)
+	assert.Equal(t, 3, results[0].Attempts)
+
+}
+
+func TestBulkSubmitterDefaultRetryPolicy(t *testing.T) {
+	client := newBulkServer(t, func(n int32, args map[string]interface{}, w http.ResponseWriter) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	// Default policy does not retry 503 of POST because the URL may be submitted twice.
+	results := collectBulkResults(urlscan.NewBulkSubmitter(client).RunFunc(context.Background(), sliceIter("https://example.com")))
+	require.Equal(t, 1, len(results))
+	assert.Error(t, results[0].Err)
+	assert.Equal(t, 1, results[0].Attempts)
+}
+
+func TestBulkSubmitterQuota(t *testing.T) {
+	client := newBulkServer(t, func(n int32, args map[string]interface{}, w http.ResponseWriter) {
+		submitOK(args, w)
+	})
+
+	submitter := urlscan.NewBulkSubmitter(client,
+		urlscan.BulkConcurrency(4),
+		urlscan.BulkQuota(100*time.Millisecond, 2),
+	)
+
+	start := time.Now()
+	results := collectBulkResults(submitter.RunFunc(context.Background(), sliceIter("https://1.example.com", "https://2.example.com", "https://3.example.com", "https://4.example.com", "https://5.example.com")))
+	require.Equal(t, 5, len(results))
+	// 2 submissions in first window, 2 in second window and 1 in third window.
+	assert.True(t, time.Since(start) >= 200*time.Millisecond)
+}
+
+func TestBulkSubmitterCanceled(t *testing.T) {
+	client := newBulkServer(t, func(n int32, args map[string]interface{}, w http.ResponseWriter) {
+		submitOK(args, w)
+	})
+
+	in := make(chan urlscan.SubmitArguments, 4)
+	for i := 0; i < 4; i++ {
+		in <- urlscan.SubmitArguments{URL: fmt.Sprintf("https://%d.example.com", i)}
+	}
+	close(in)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	submitter := urlscan.NewBulkSubmitter(client, urlscan.BulkConcurrency(1), urlscan.BulkQuota(time.Hour, 1))
+	ch := submitter.Run(ctx, in)
+
+	first := <-ch
+	require.NoError(t, first.Err)
+	cancel()
+
+	// All inputs are reported even if they were not submitted.
+	rest := collectBulkResults(ch)
+	require.Equal(t, 3, len(rest))
+	for i, res := range rest {
+		assert.Equal(t, i+1, res.Index)
+		assert.Equal(t, fmt.Sprintf("https://%d.example.com", i+1), res.Args.URL)
+		assert.Nil(t, res.Task)
+		assert.True(t, errors.Is(res.Err, context.Canceled), "err: %v", res.Err)
+	}
+}
+
+func TestBulkSubmitterRunFuncCanceled(t *testing.T) {
+	client := newBulkServer(t, func(n int32, args map[string]interface{}, w http.ResponseWriter) {
+		submitOK(args, w)
+	})
+
+	var pulled int
+	next := func() (urlscan.SubmitArguments, bool) {
+		pulled++
+		return urlscan.SubmitArguments{URL: fmt.Sprintf("https://%d.example.com", pulled)}, true
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	submitter := urlscan.NewBulkSubmitter(client, urlscan.BulkConcurrency(1), urlscan.BulkQuota(time.Hour, 1))
+	ch := submitter.RunFunc(ctx, next)
+
+	first := <-ch
+	require.NoError(t, first.Err)
+	cancel()
+
+	rest := collectBulkResults(ch)
+	// Every argument taken from the iterator has a result.
+	assert.Equal(t, pulled, len(rest)+1)
+	for _, res := range rest {
+		assert.True(t, errors.Is(res.Err, context.Canceled), "err: %v", res.Err)
+	}
+}
+
+func TestBulkSubmitterDoesNotRetryInClient(t *testing.T) {
+	var count int32
+	client := newBulkServer(t, func(n int32, args map[string]interface{}, w http.ResponseWriter) {
+		atomic.AddInt32(&count, 1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"message":"Rate limit for this action exceeded","status":429}`))
+	}, urlscan.WithRateLimitRetry(3), urlscan.WithRetryPolicy(urlscan.ExponentialRetryPolicy{RetryNonIdempotent: true}))
+
+	submitter := urlscan.NewBulkSubmitter(client, urlscan.BulkMaxRateLimitRetry(1))
+	results := collectBulkResults(submitter.RunFunc(context.Background(), sliceIter("https://example.com")))
+
+	require.Equal(t, 1, len(results))
+	assert.True(t, urlscan.IsRateLimited(results[0].Err))
+	assert.Equal(t, 2, results[0].Attempts)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&count))
+}
+
+func sliceIter(urls ...string) func() (urlscan.SubmitArguments, bool) {
+	return func() (urlscan.SubmitArguments, bool) {
+		if len(urls) == 0 {
+			return urlscan.SubmitArguments{}, false
+		}
+		args := urlscan.SubmitArguments{URL: urls[0]}
+		urls = urls[1:]
+		return args, true
+	}
+}
+
+func TestBulkSubmitterTaskUsesClient(t *testing.T) {
+	var polls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			w.Write([]byte(`{"uuid":"` + testScanUUID + `"}`))
+			return
+		}
+
+		switch atomic.AddInt32(&polls, 1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"message":"Rate limit for this action exceeded","status":429}`))
+		default:
+			w.Write([]byte(`{"task":{"uuid":"` + testScanUUID + `"}}`))
+		}
+	}))
+	defer server.Close()
+
+	client := urlscan.NewClient("test-api-key", urlscan.WithBaseURL(server.URL),
+		urlscan.WithRetryPolicy(urlscan.ExponentialRetryPolicy{BaseDelay: time.Millisecond}),
+		urlscan.WithRateLimitRetry(1),
+	)
+
+	results := collectBulkResults(urlscan.NewBulkSubmitter(&client).RunFunc(context.Background(), sliceIter("https://example.com")))
+	require.Equal(t, 1, len(results))
+	require.NoError(t, results[0].Err)
+
+	// 503 and 429 of result API are retried by the Client, not by polling.
+	result, err := results[0].Task.Await(context.Background(), urlscan.WaitMaxRetry(1))
+	require.NoError(t, err)
+	assert.Equal(t, urlscan.TaskStatusDone, result.Status)
+	assert.Equal(t, 1, result.Attempts)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&polls))
+}
+
+func TestBulkSubmitterZeroQuota(t *testing.T) {
+	client := newBulkServer(t, func(n int32, args map[string]interface{}, w http.ResponseWriter) {
+		submitOK(args, w)
+	})
+
+	submitter := urlscan.NewBulkSubmitter(client, urlscan.BulkQuota(time.Minute, 0))
+	results := collectBulkResults(submitter.RunFunc(context.Background(), sliceIter("https://example.com")))
+	require.Equal(t, 1, len(results))
+	assert.NoError(t, results[0].Err)
+}
+
+func TestBulkSubmitterCanceledWhileRetrying(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := newBulkServer(t, func(n int32, args map[string]interface{}, w http.ResponseWriter) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		cancel()
+	})
+
+	policy := urlscan.ExponentialRetryPolicy{BaseDelay: time.Hour, RetryNonIdempotent: true}
+	submitter := urlscan.NewBulkSubmitter(client, urlscan.BulkRetryPolicy(policy))
+	results := collectBulkResults(submitter.RunFunc(ctx, sliceIter("https://example.com")))
+	require.Equal(t, 1, len(results))
+	assert.True(t, errors.Is(results[0].Err, context.Canceled), "err: %v", results[0].Err)
+	assert.Equal(t, 1, results[0].Attempts)
+}
+
+func TestBulkSubmitterRetryClientTimeout(t *testing.T) {
+	client := newBulkServer(t, func(n int32, args map[string]interface{}, w http.ResponseWriter) {
+		if n == 1 {
+			time.Sleep(300 * time.Millisecond)
+		}
+		submitOK(args, w)
+	}, urlscan.WithTimeout(100*time.Millisecond))
+
+	policy := urlscan.ExponentialRetryPolicy{BaseDelay: time.Millisecond, RetryNonIdempotent: true}
+	submitter := urlscan.NewBulkSubmitter(client, urlscan.BulkRetryPolicy(policy))
+	results := collectBulkResults(submitter.RunFunc(context.Background(), sliceIter("https://example.com")))
+	require.Equal(t, 1, len(results))
+	require.NoError(t, results[0].Err)
+	assert.Equal(t, 2, results[0].Attempts)
+}
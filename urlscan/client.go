@@ -2,33 +2,29 @@ package urlscan
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
-	"github.com/sirupsen/logrus"
 )
 
-// Logger is a logrus logger. You can replace the logger with yours or change setting if you need.
-var Logger = logrus.New()
-
-func init() {
-	Logger.SetLevel(logrus.PanicLevel)
-	Logger.SetFormatter(&logrus.TextFormatter{
-		DisableColors: false,
-		FullTimestamp: true,
-	})
-	Logger.SetReportCaller(true)
-}
-
 // String converts string variable and literal to pointer
 func String(s string) *string {
 	return &s
 }
 
+// Bool converts bool variable and literal to pointer
+func Bool(b bool) *bool {
+	return &b
+}
+
 // Uint64 converts uint64 variable and literal to pointer
 func Uint64(u uint64) *uint64 {
 	return &u
@@ -36,101 +32,308 @@ func Uint64(u uint64) *uint64 {
 
 // Client is main structure of the library, a requester to urlscan.io.
 type Client struct {
-	apiKey  string
-	BaseURL string
+	apiKey            string
+	httpClient        *http.Client
+	userAgent         string
+	rateLimiter       *rateLimiter
+	retryPolicy       RetryPolicy
+	retryHook         func(RetryAttempt)
+	logger            Logger
+	logRequestBody    bool
+	wait              *waitConfig
+	unknownFieldsHook func(uuid string, fields []string)
+	// noRateLimitRetry disables retry of 429 response regardless of WithRateLimitRetry.
+	noRateLimitRetry bool
+	BaseURL          string
+}
+
+// DefaultBaseURL is endpoint of urlscan.io API v1.
+const DefaultBaseURL = "https://urlscan.io/api/v1"
+
+// DefaultUserAgent is sent as User-Agent header unless WithUserAgent is given.
+const DefaultUserAgent = "urlscan-go"
+
+// Option is a functional option of NewClient.
+type Option func(*Client)
+
+// WithHTTPClient replaces http.Client used for all requests of the Client.
+// The given client is shared, not copied, so connection pool and cookie jar are reused.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(x *Client) {
+		x.httpClient = httpClient
+	}
 }
 
-// NewClient is a constructor of Client
-func NewClient(apiKey string) Client {
+// WithTimeout sets timeout of each HTTP request.
+func WithTimeout(timeout time.Duration) Option {
+	return func(x *Client) {
+		c := *x.doer()
+		c.Timeout = timeout
+		x.httpClient = &c
+	}
+}
+
+// WithTransport sets http.RoundTripper, e.g. *http.Transport with custom TLS roots or connection pooling.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(x *Client) {
+		c := *x.doer()
+		c.Transport = transport
+		x.httpClient = &c
+	}
+}
+
+// WithProxy sends all requests via proxyURL.
+func WithProxy(proxyURL *url.URL) Option {
+	return func(x *Client) {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyURL(proxyURL)
+		WithTransport(transport)(x)
+	}
+}
+
+// WithUserAgent sets User-Agent header of requests to urlscan.io.
+func WithUserAgent(userAgent string) Option {
+	return func(x *Client) {
+		x.userAgent = userAgent
+	}
+}
+
+// WithBaseURL changes endpoint of urlscan.io API. Default is DefaultBaseURL.
+func WithBaseURL(baseURL string) Option {
+	return func(x *Client) {
+		x.BaseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// NewClient is a constructor of Client. Options are applied in given order.
+func NewClient(apiKey string, opts ...Option) Client {
 	client := Client{
-		apiKey:  apiKey,
-		BaseURL: "https://urlscan.io/api/v1",
+		apiKey:      apiKey,
+		httpClient:  &http.Client{},
+		userAgent:   DefaultUserAgent,
+		rateLimiter: newRateLimiter(),
+		BaseURL:     DefaultBaseURL,
+	}
+
+	for _, opt := range opts {
+		opt(&client)
 	}
 
 	return client
 }
 
-func (x Client) post(apiName string, input interface{}, output interface{}) (int, error) {
-	rawData, err := json.Marshal(input)
+// newRequest builds a request with common headers of the Client.
+func (x Client) newRequest(ctx context.Context, method, uri string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, uri, body)
 	if err != nil {
-		return 0, errors.Wrap(err, "Fail to marshal urlscan.io submit argument")
+		return nil, err
 	}
 
-	uri := fmt.Sprintf("%s/%s/", x.BaseURL, apiName)
-	Logger.WithFields(logrus.Fields{
-		"uri":  uri,
-		"body": string(rawData),
-	}).Debug("Generated Query")
+	if x.userAgent != "" {
+		req.Header.Set("User-Agent", x.userAgent)
+	}
+	return req, nil
+}
 
-	client := &http.Client{}
-	req, err := http.NewRequest("POST", uri, bytes.NewReader(rawData))
-	if err != nil {
-		return 0, errors.Wrap(err, "Fail to create urlscan.io scan POST request")
+// doer returns http.Client for requests. Client created without NewClient falls back to a zero value http.Client.
+func (x Client) doer() *http.Client {
+	if x.httpClient == nil {
+		return &http.Client{}
 	}
+	return x.httpClient
+}
 
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("API-Key", x.apiKey)
+// limiter returns rateLimiter of the Client. Client created without NewClient does not track rate limit.
+func (x Client) limiter() *rateLimiter {
+	if x.rateLimiter == nil {
+		return newRateLimiter()
+	}
+	return x.rateLimiter
+}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return resp.StatusCode, errors.Wrap(err, "Fail to send urlscan.io POST request")
+// log returns Logger of the Client that redacts API key.
+func (x Client) log() Logger {
+	if x.logger == nil {
+		return nopLogger{}
 	}
+	return redactLogger{logger: x.logger, apiKey: x.apiKey}
+}
 
-	defer resp.Body.Close()
-	buf, err := ioutil.ReadAll(resp.Body)
+func (x Client) post(ctx context.Context, apiName string, target rateTarget, input interface{}, output interface{}) (int, error) {
+	rawData, err := json.Marshal(input)
 	if err != nil {
-		return resp.StatusCode, errors.Wrap(err, "Fail to read urlscan.io POST result")
+		return 0, errors.Wrap(err, "Fail to marshal urlscan.io submit argument")
 	}
-	if resp.StatusCode != 200 {
-		Logger.WithFields(logrus.Fields{
-			"body": string(buf),
-			"code": resp.StatusCode,
-		}).Warn("Unexpected status code")
+
+	uri := fmt.Sprintf("%s/%s/", x.BaseURL, apiName)
+	body := redacted
+	if x.logRequestBody {
+		body = string(rawData)
 	}
+	x.log().Debug("Generated Query", "uri", uri, "body", body)
 
-	err = json.Unmarshal(buf, &output)
+	req, err := x.newRequest(ctx, "POST", uri, bytes.NewReader(rawData))
 	if err != nil {
-		return resp.StatusCode, errors.Wrap(err, "Fail to unmarshal urlscan.io POST result")
+		return 0, errors.Wrap(err, "Fail to create urlscan.io scan POST request")
 	}
 
-	return resp.StatusCode, nil
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("API-Key", x.apiKey)
+
+	return x.do(req, target, output)
 }
 
-func (x Client) get(apiName string, values url.Values, output interface{}) (int, error) {
+func (x Client) get(ctx context.Context, apiName string, values url.Values, output interface{}) (int, error) {
 	var qs string
 	if values != nil {
 		qs = "?" + values.Encode()
 	}
 
 	uri := fmt.Sprintf("%s/%s/%s", x.BaseURL, apiName, qs)
-	Logger.WithField("uri", uri).Info("Generated Query")
+	x.log().Debug("Generated Query", "uri", uri)
 
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", uri, nil)
+	req, err := x.newRequest(ctx, "GET", uri, nil)
 	if err != nil {
 		return 0, errors.Wrap(err, "Fail to create urlscan.io get request")
 	}
 
-	resp, err := client.Do(req)
+	return x.do(req, targetOf(apiName), output)
+}
+
+// targetOf returns rate limit target of GET API such as "search" and "result/{uuid}".
+func targetOf(apiName string) rateTarget {
+	path := strings.SplitN(apiName, "/", 2)[0]
+	switch path {
+	case RateLimitActionResult:
+		return rateTarget{path: path, expected: RateLimitActionRetrieve}
+	case RateLimitActionSearch:
+		return rateTarget{path: path, expected: RateLimitActionSearch}
+	default:
+		return rateTarget{path: path}
+	}
+}
+
+// do sends req and decodes response body into output. If status code is not 200, it returns *APIError.
+// Rate limit status of target is updated by the response. 429 response is retried according to
+// WithRateLimitRetry and other failures are retried according to RetryPolicy.
+func (x Client) do(req *http.Request, target rateTarget, output interface{}) (int, error) {
+	limiter := x.limiter()
+	var rateLimitRetry int
+
+	for i := 1; ; i++ {
+		if d := limiter.budgetDelay(target); d > 0 {
+			x.log().Debug("Wait for rate limit reset", "path", target.path, "action", target.expected, "delay", d)
+			if err := sleepContext(req.Context(), d); err != nil {
+				return 0, errors.Wrap(err, "Interrupted while waiting rate limit reset")
+			}
+		}
+
+		code, err := x.send(req, limiter, target, output)
+
+		attempt := RetryAttempt{
+			Method:     req.Method,
+			URL:        req.URL.String(),
+			Attempt:    i,
+			StatusCode: code,
+			Err:        err,
+			Idempotent: req.Method == "GET" || req.Method == "HEAD",
+		}
+
+		switch {
+		case err == nil || req.Context().Err() != nil:
+			// Timeout of http.Client also matches context.DeadlineExceeded, so check the context of the request itself.
+		case IsRateLimited(err):
+			if !x.noRateLimitRetry && rateLimitRetry < limiter.maxRetry {
+				apiErr, _ := asAPIError(err)
+				attempt.Delay, attempt.Retrying = retryDelay(apiErr.RateLimit), true
+				rateLimitRetry++
+			}
+		case x.retryPolicy != nil:
+			attempt.Delay, attempt.Retrying = x.retryPolicy.Retry(attempt)
+		}
+
+		if x.retryHook != nil {
+			x.retryHook(attempt)
+		}
+		if !attempt.Retrying {
+			return code, err
+		}
+
+		x.log().Debug("Retrying request", "path", target.path, "delay", attempt.Delay, "code", code, "error", err)
+
+		if err := sleepContext(req.Context(), attempt.Delay); err != nil {
+			return code, errors.Wrap(err, "Interrupted while waiting retry")
+		}
+
+		if req, err = rewindRequest(req); err != nil {
+			return code, err
+		}
+	}
+}
+
+// rewindRequest returns a copy of req with fresh body to send it again.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	newReq := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, errors.Wrap(err, "Fail to rewind request body")
+		}
+		newReq.Body = body
+	}
+	return newReq, nil
+}
+
+// streamOutput is an output of API that decodes response body incrementally instead of reading whole body at once.
+type streamOutput interface {
+	decodeStream(resp *http.Response) error
+}
+
+// bodyReader records an error of reading response body to tell it from a decode or write error.
+type bodyReader struct {
+	r   io.Reader
+	err error
+}
+
+func (x *bodyReader) Read(p []byte) (int, error) {
+	n, err := x.r.Read(p)
+	if err != nil && err != io.EOF {
+		x.err = err
+	}
+	return n, err
+}
+
+func (x Client) send(req *http.Request, limiter *rateLimiter, target rateTarget, output interface{}) (int, error) {
+	resp, err := x.doer().Do(req)
 	if err != nil {
-		return resp.StatusCode, errors.Wrap(err, "Fail to send urlscan.io get request")
+		return 0, newNetworkError(req, err)
 	}
 	defer resp.Body.Close()
+
+	limiter.update(target, resp.Header)
+
+	if stream, ok := output.(streamOutput); ok && resp.StatusCode == 200 {
+		if err := stream.decodeStream(resp); err != nil {
+			return resp.StatusCode, errors.Wrapf(err, "Fail to decode urlscan.io %s result", req.Method)
+		}
+		return resp.StatusCode, nil
+	}
+
 	buf, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return resp.StatusCode, errors.Wrap(err, "Fail to read urlscan.io get result")
+		return resp.StatusCode, errors.Wrapf(err, "Fail to read urlscan.io %s result", req.Method)
 	}
 
-	if resp.StatusCode != 200 && resp.StatusCode != 404 {
-		Logger.WithFields(logrus.Fields{
-			"body": string(buf),
-			"code": resp.StatusCode,
-		}).Warn("Unexpected status code")
+	if resp.StatusCode != 200 {
+		if resp.StatusCode != 404 {
+			x.log().Warn("Unexpected status code", "body", string(buf), "code", resp.StatusCode)
+		}
+		return resp.StatusCode, newAPIError(req, resp, buf)
 	}
 
 	err = json.Unmarshal(buf, &output)
 	if err != nil {
-		return resp.StatusCode, errors.Wrap(err, "Fail to unmarshal urlscan.io get result")
+		return resp.StatusCode, errors.Wrapf(err, "Fail to unmarshal urlscan.io %s result", req.Method)
 	}
 
 	return resp.StatusCode, nil
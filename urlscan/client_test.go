@@ -1,12 +1,16 @@
 package urlscan_test
 
 import (
-	"log"
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/m-mizutani/urlscan-go/urlscan"
-	"github.com/sirupsen/logrus"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -18,15 +22,28 @@ var cfg config
 
 func init() {
 	cfg.ApiKey = os.Getenv("URLSCAN_API_KEY")
+}
+
+// requireAPIKey skips tests accessing to real urlscan.io if no API key is given.
+func requireAPIKey(t *testing.T) {
 	if cfg.ApiKey == "" {
-		log.Fatal("no API KEY, environment variable URLSCAN_API_KEY is required.")
+		t.Skip("no API KEY, environment variable URLSCAN_API_KEY is required.")
 	}
+}
+
+// newTestClient returns a client sending requests to a local server with handler.
+func newTestClient(t *testing.T, handler http.Handler) urlscan.Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
 
-	urlscan.Logger = logrus.New()
-	urlscan.Logger.SetLevel(logrus.InfoLevel)
+	client := urlscan.NewClient("test-api-key")
+	client.BaseURL = server.URL
+	return client
 }
 
 func TestSubmitScan(t *testing.T) {
+	requireAPIKey(t)
+
 	client := urlscan.NewClient(cfg.ApiKey)
 	task, err := client.Submit(urlscan.SubmitArguments{
 		URL: "https://cookpad.com",
@@ -36,3 +53,87 @@ func TestSubmitScan(t *testing.T) {
 	err = task.Wait()
 	require.NoError(t, err)
 }
+
+func TestSubmitContextCanceled(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err := client.SubmitContext(ctx, urlscan.SubmitArguments{URL: "https://example.com"})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestWaitContextCanceledWhileSleeping(t *testing.T) {
+	var called int
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called++
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"not found","status":404}`))
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	task := client.ResultTask("0c5ba6ab-1234-4e72-9f4a-4d0d2bd6c0a1")
+	start := time.Now()
+	err := task.WaitContext(ctx)
+	require.Error(t, err)
+	assert.True(t, time.Since(start) < time.Second)
+	assert.Equal(t, 1, called)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestClientOptions(t *testing.T) {
+	var userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		w.Write([]byte(`{"results":[],"total":0}`))
+	}))
+	defer server.Close()
+
+	var viaTransport bool
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		viaTransport = true
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	client := urlscan.NewClient("test-api-key",
+		urlscan.WithBaseURL(server.URL+"/"),
+		urlscan.WithUserAgent("my-agent/1.0"),
+		urlscan.WithTransport(transport),
+		urlscan.WithTimeout(time.Second),
+	)
+
+	_, err := client.Search(urlscan.SearchArguments{Query: urlscan.String("domain:example.com")})
+	require.NoError(t, err)
+	assert.Equal(t, "my-agent/1.0", userAgent)
+	assert.True(t, viaTransport)
+}
+
+func TestClientWithHTTPClientTimeout(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	client = urlscan.NewClient("test-api-key",
+		urlscan.WithBaseURL(client.BaseURL),
+		urlscan.WithHTTPClient(&http.Client{Timeout: 50 * time.Millisecond}),
+	)
+
+	_, err := client.Search(urlscan.SearchArguments{})
+	require.Error(t, err)
+}
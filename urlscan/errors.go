@@ -0,0 +1,177 @@
+package urlscan
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// APIFieldError is an item of "errors" array in error response of urlscan.io.
+type APIFieldError struct {
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+	Status int    `json:"status"`
+}
+
+// RateLimit presents X-Rate-Limit-* headers returned by urlscan.io.
+type RateLimit struct {
+	// Scope is "team" or "ip" for example.
+	Scope string
+	// Action is rate limited action such as "search", "public", "unlisted", "private" and "retrieve".
+	Action string
+	// Window is a period of the limit, "minute", "hour" or "day".
+	Window string
+	// Limit is maximum number of requests in the Window.
+	Limit int64
+	// Remaining is a number of requests still allowed in the Window.
+	Remaining int64
+	// Reset is time when the Window is reset.
+	Reset time.Time
+	// RetryAfter is given by Retry-After header of 429 response.
+	RetryAfter time.Duration
+}
+
+// parseRateLimit extracts rate limit information from response headers. The second returned value is false if no rate limit header exists.
+func parseRateLimit(header http.Header) (RateLimit, bool) {
+	var rl RateLimit
+	var found bool
+
+	get := func(key string) string {
+		v := header.Get(key)
+		if v != "" {
+			found = true
+		}
+		return v
+	}
+
+	rl.Scope = get("X-Rate-Limit-Scope")
+	rl.Action = get("X-Rate-Limit-Action")
+	rl.Window = get("X-Rate-Limit-Window")
+	if v, err := strconv.ParseInt(get("X-Rate-Limit-Limit"), 10, 64); err == nil {
+		rl.Limit = v
+	}
+	if v, err := strconv.ParseInt(get("X-Rate-Limit-Remaining"), 10, 64); err == nil {
+		rl.Remaining = v
+	}
+	if v := get("X-Rate-Limit-Reset"); v != "" {
+		if ts, err := time.Parse(time.RFC3339, v); err == nil {
+			rl.Reset = ts
+		}
+	}
+	if rl.Reset.IsZero() {
+		if v, err := strconv.ParseFloat(get("X-Rate-Limit-Reset-After"), 64); err == nil {
+			rl.Reset = time.Now().Add(time.Duration(v * float64(time.Second)))
+		}
+	}
+	if v := get("Retry-After"); v != "" {
+		if sec, err := strconv.ParseInt(v, 10, 64); err == nil {
+			rl.RetryAfter = time.Duration(sec) * time.Second
+		} else if ts, err := http.ParseTime(v); err == nil {
+			rl.RetryAfter = time.Until(ts)
+		}
+	}
+
+	return rl, found
+}
+
+// APIError is returned when urlscan.io responds with non 200 status code.
+// Use errors.As to retrieve it from returned error.
+type APIError struct {
+	StatusCode  int             `json:"status"`
+	Message     string          `json:"message"`
+	Description string          `json:"description"`
+	Errors      []APIFieldError `json:"errors"`
+
+	// Method and URL of the request.
+	Method string `json:"-"`
+	URL    string `json:"-"`
+	// RateLimit is set from response headers.
+	RateLimit RateLimit `json:"-"`
+	// Body is raw response body.
+	Body []byte `json:"-"`
+}
+
+func newAPIError(req *http.Request, resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{}
+	if err := json.Unmarshal(body, apiErr); err != nil {
+		apiErr.Message = strings.TrimSpace(string(body))
+	}
+
+	apiErr.StatusCode = resp.StatusCode
+	apiErr.Method = req.Method
+	apiErr.URL = req.URL.String()
+	apiErr.RateLimit, _ = parseRateLimit(resp.Header)
+	apiErr.Body = body
+	return apiErr
+}
+
+func (x *APIError) Error() string {
+	msg := fmt.Sprintf("urlscan.io API error: status %d", x.StatusCode)
+	if x.Message != "" {
+		msg += ", " + x.Message
+	}
+	if x.Description != "" {
+		msg += " (" + x.Description + ")"
+	}
+	for _, e := range x.Errors {
+		msg += fmt.Sprintf("; %s: %s", e.Title, e.Detail)
+	}
+	return msg
+}
+
+func (x *APIError) contains(keywords ...string) bool {
+	text := strings.ToLower(x.Message + " " + x.Description)
+	for _, e := range x.Errors {
+		text += " " + strings.ToLower(e.Title+" "+e.Detail)
+	}
+
+	for _, k := range keywords {
+		if strings.Contains(text, k) {
+			return true
+		}
+	}
+	return false
+}
+
+func asAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
+// IsNotFound returns true if err is caused by 404 response, e.g. scan result is not ready yet.
+func IsNotFound(err error) bool {
+	apiErr, ok := asAPIError(err)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsRateLimited returns true if err is caused by 429 response.
+func IsRateLimited(err error) bool {
+	apiErr, ok := asAPIError(err)
+	return ok && apiErr.StatusCode == http.StatusTooManyRequests
+}
+
+// IsQuotaExceeded returns true if err is caused by exceeding daily quota. It is a kind of rate limit and also IsRateLimited returns true.
+func IsQuotaExceeded(err error) bool {
+	apiErr, ok := asAPIError(err)
+	if !ok || apiErr.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	return apiErr.RateLimit.Window == "day" || apiErr.contains("quota")
+}
+
+// IsBlockedURL returns true if urlscan.io refused to scan the submitted URL, e.g. the domain is in a blocklist.
+func IsBlockedURL(err error) bool {
+	apiErr, ok := asAPIError(err)
+	if !ok || apiErr.StatusCode != http.StatusBadRequest {
+		return false
+	}
+	return apiErr.contains("prevented", "blocked", "blacklist")
+}
@@ -0,0 +1,102 @@
+package urlscan_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/m-mizutani/urlscan-go/urlscan"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newErrorClient(t *testing.T, code int, header map[string]string, body string) urlscan.Client {
+	return newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for k, v := range header {
+			w.Header().Set(k, v)
+		}
+		w.WriteHeader(code)
+		w.Write([]byte(body))
+	}))
+}
+
+func TestAPIErrorOfSubmit(t *testing.T) {
+	client := newErrorClient(t, 400, nil, `{
+		"message": "Missing URL properties",
+		"description": "The URL supplied was not OK, please specify it including the protocol, host and path",
+		"status": 400,
+		"errors": [{"title": "url is invalid", "detail": "url must be valid", "status": 400}]
+	}`)
+
+	_, err := client.Submit(urlscan.SubmitArguments{URL: "example"})
+	require.Error(t, err)
+
+	var apiErr *urlscan.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, 400, apiErr.StatusCode)
+	assert.Equal(t, "Missing URL properties", apiErr.Message)
+	assert.Equal(t, "POST", apiErr.Method)
+	assert.Contains(t, apiErr.URL, "/scan/")
+	require.Equal(t, 1, len(apiErr.Errors))
+	assert.Equal(t, "url is invalid", apiErr.Errors[0].Title)
+	assert.False(t, urlscan.IsBlockedURL(err))
+}
+
+func TestAPIErrorBlockedURL(t *testing.T) {
+	client := newErrorClient(t, 400, nil, `{
+		"message": "Scan prevented ...",
+		"description": "The domain example.com is blocked from scanning",
+		"status": 400
+	}`)
+
+	_, err := client.Submit(urlscan.SubmitArguments{URL: "https://example.com"})
+	assert.True(t, urlscan.IsBlockedURL(err))
+	assert.False(t, urlscan.IsRateLimited(err))
+}
+
+func TestAPIErrorRateLimited(t *testing.T) {
+	header := map[string]string{
+		"X-Rate-Limit-Scope":     "team",
+		"X-Rate-Limit-Action":    "search",
+		"X-Rate-Limit-Window":    "minute",
+		"X-Rate-Limit-Limit":     "120",
+		"X-Rate-Limit-Remaining": "0",
+		"X-Rate-Limit-Reset":     "2020-05-18T20:20:00.000Z",
+		"Retry-After":            "12",
+	}
+	client := newErrorClient(t, 429, header, `{"message":"Rate limit for this action exceeded","status":429}`)
+
+	_, err := client.Search(urlscan.SearchArguments{})
+	require.Error(t, err)
+	assert.True(t, urlscan.IsRateLimited(err))
+	assert.False(t, urlscan.IsQuotaExceeded(err))
+
+	var apiErr *urlscan.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "search", apiErr.RateLimit.Action)
+	assert.Equal(t, int64(120), apiErr.RateLimit.Limit)
+	assert.Equal(t, int64(0), apiErr.RateLimit.Remaining)
+	assert.Equal(t, 2020, apiErr.RateLimit.Reset.Year())
+	assert.Equal(t, "12s", apiErr.RateLimit.RetryAfter.String())
+}
+
+func TestAPIErrorQuotaExceeded(t *testing.T) {
+	header := map[string]string{"X-Rate-Limit-Window": "day"}
+	client := newErrorClient(t, 429, header, `{"message":"Rate limit for this action exceeded","status":429}`)
+
+	_, err := client.Submit(urlscan.SubmitArguments{URL: "https://example.com"})
+	assert.True(t, urlscan.IsRateLimited(err))
+	assert.True(t, urlscan.IsQuotaExceeded(err))
+}
+
+func TestAPIErrorNotFound(t *testing.T) {
+	client := newErrorClient(t, 404, nil, `<html>not json</html>`)
+
+	task := client.ResultTask("0c5ba6ab-1234-4e72-9f4a-4d0d2bd6c0a1")
+	err := task.Get()
+	assert.True(t, urlscan.IsNotFound(err))
+
+	var apiErr *urlscan.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "<html>not json</html>", apiErr.Message)
+}
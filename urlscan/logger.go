@@ -0,0 +1,141 @@
+package urlscan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Logger is a structured logger used by Client. keysAndValues are alternating key and value pairs
+// such as ("uri", uri, "code", 200). *slog.Logger of log/slog satisfies the interface as it is.
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+// WithLogger sets Logger of the Client. By default, the Client outputs no log.
+func WithLogger(logger Logger) Option {
+	return func(x *Client) {
+		x.logger = logger
+	}
+}
+
+// WithLogRequestBody makes the Client output request body in debug log. Request body is redacted by default.
+func WithLogRequestBody() Option {
+	return func(x *Client) {
+		x.logRequestBody = true
+	}
+}
+
+type nopLogger struct{}
+
+func (x nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (x nopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (x nopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (x nopLogger) Error(msg string, keysAndValues ...interface{}) {}
+
+const redacted = "[REDACTED]"
+
+// redactLogger replaces API key in string values with redacted mark to prevent leaking the key via log.
+type redactLogger struct {
+	logger Logger
+	apiKey string
+}
+
+func (x redactLogger) redact(keysAndValues []interface{}) []interface{} {
+	if x.apiKey == "" {
+		return keysAndValues
+	}
+
+	values := make([]interface{}, len(keysAndValues))
+	for i, v := range keysAndValues {
+		if s, ok := v.(string); ok {
+			v = strings.Replace(s, x.apiKey, redacted, -1)
+		}
+		values[i] = v
+	}
+	return values
+}
+
+func (x redactLogger) Debug(msg string, keysAndValues ...interface{}) {
+	x.logger.Debug(msg, x.redact(keysAndValues)...)
+}
+func (x redactLogger) Info(msg string, keysAndValues ...interface{}) {
+	x.logger.Info(msg, x.redact(keysAndValues)...)
+}
+func (x redactLogger) Warn(msg string, keysAndValues ...interface{}) {
+	x.logger.Warn(msg, x.redact(keysAndValues)...)
+}
+func (x redactLogger) Error(msg string, keysAndValues ...interface{}) {
+	x.logger.Error(msg, x.redact(keysAndValues)...)
+}
+
+// logrusLogger is an adapter of logrus.
+type logrusLogger struct {
+	logger logrus.FieldLogger
+}
+
+// NewLogrusLogger wraps logrus logger (*logrus.Logger or *logrus.Entry) as Logger.
+func NewLogrusLogger(logger logrus.FieldLogger) Logger {
+	return &logrusLogger{logger: logger}
+}
+
+func toLogrusFields(keysAndValues []interface{}) logrus.Fields {
+	fields := logrus.Fields{}
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key := fmt.Sprint(keysAndValues[i])
+		if i+1 < len(keysAndValues) {
+			fields[key] = keysAndValues[i+1]
+		} else {
+			fields[key] = nil
+		}
+	}
+	return fields
+}
+
+func (x *logrusLogger) Debug(msg string, keysAndValues ...interface{}) {
+	x.logger.WithFields(toLogrusFields(keysAndValues)).Debug(msg)
+}
+func (x *logrusLogger) Info(msg string, keysAndValues ...interface{}) {
+	x.logger.WithFields(toLogrusFields(keysAndValues)).Info(msg)
+}
+func (x *logrusLogger) Warn(msg string, keysAndValues ...interface{}) {
+	x.logger.WithFields(toLogrusFields(keysAndValues)).Warn(msg)
+}
+func (x *logrusLogger) Error(msg string, keysAndValues ...interface{}) {
+	x.logger.WithFields(toLogrusFields(keysAndValues)).Error(msg)
+}
+
+// ZapSugaredLogger is a subset of *zap.SugaredLogger methods. It is defined to avoid depending on zap.
+type ZapSugaredLogger interface {
+	Debugw(msg string, keysAndValues ...interface{})
+	Infow(msg string, keysAndValues ...interface{})
+	Warnw(msg string, keysAndValues ...interface{})
+	Errorw(msg string, keysAndValues ...interface{})
+}
+
+// zapLogger is an adapter of zap.
+type zapLogger struct {
+	logger ZapSugaredLogger
+}
+
+// NewZapLogger wraps *zap.SugaredLogger as Logger. Use zap.Logger.Sugar() to get it from *zap.Logger.
+func NewZapLogger(logger ZapSugaredLogger) Logger {
+	return &zapLogger{logger: logger}
+}
+
+func (x *zapLogger) Debug(msg string, keysAndValues ...interface{}) {
+	x.logger.Debugw(msg, keysAndValues...)
+}
+func (x *zapLogger) Info(msg string, keysAndValues ...interface{}) {
+	x.logger.Infow(msg, keysAndValues...)
+}
+func (x *zapLogger) Warn(msg string, keysAndValues ...interface{}) {
+	x.logger.Warnw(msg, keysAndValues...)
+}
+func (x *zapLogger) Error(msg string, keysAndValues ...interface{}) {
+	x.logger.Errorw(msg, keysAndValues...)
+}
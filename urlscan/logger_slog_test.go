@@ -0,0 +1,27 @@
+//go:build go1.21
+// +build go1.21
+
+package urlscan_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/m-mizutani/urlscan-go/urlscan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// log/slog is available since Go 1.21.
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := newLoggingClient(t, "secret-api-key", urlscan.WithLogger(logger))
+
+	_, err := client.Search(urlscan.SearchArguments{})
+	require.Error(t, err)
+	assert.Contains(t, buf.String(), "Generated Query")
+	assert.NotContains(t, buf.String(), "secret-api-key")
+}
@@ -0,0 +1,98 @@
+package urlscan_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/urlscan-go/urlscan"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordLogger struct {
+	records []string
+}
+
+func (x *recordLogger) record(level, msg string, keysAndValues []interface{}) {
+	x.records = append(x.records, fmt.Sprint(append([]interface{}{level, msg}, keysAndValues...)...))
+}
+
+func (x *recordLogger) Debug(msg string, kv ...interface{}) { x.record("DEBUG", msg, kv) }
+func (x *recordLogger) Info(msg string, kv ...interface{})  { x.record("INFO", msg, kv) }
+func (x *recordLogger) Warn(msg string, kv ...interface{})  { x.record("WARN", msg, kv) }
+func (x *recordLogger) Error(msg string, kv ...interface{}) { x.record("ERROR", msg, kv) }
+
+func (x *recordLogger) String() string {
+	return strings.Join(x.records, "\n")
+}
+
+func newLoggingClient(t *testing.T, apiKey string, opts ...urlscan.Option) urlscan.Client {
+	server := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"message":"invalid key ` + r.Header.Get("API-Key") + `","status":400}`))
+	}))
+	return urlscan.NewClient(apiKey, append([]urlscan.Option{urlscan.WithBaseURL(server.BaseURL)}, opts...)...)
+}
+
+func TestLoggerRedactsAPIKeyAndBody(t *testing.T) {
+	logger := &recordLogger{}
+	client := newLoggingClient(t, "secret-api-key", urlscan.WithLogger(logger))
+
+	_, err := client.Submit(urlscan.SubmitArguments{URL: "https://example.com/private-path"})
+	require.Error(t, err)
+
+	log := logger.String()
+	assert.Contains(t, log, "Generated Query")
+	assert.Contains(t, log, "Unexpected status code")
+	assert.NotContains(t, log, "secret-api-key")
+	assert.NotContains(t, log, "private-path")
+}
+
+func TestLoggerWithLogRequestBody(t *testing.T) {
+	logger := &recordLogger{}
+	client := newLoggingClient(t, "secret-api-key", urlscan.WithLogger(logger), urlscan.WithLogRequestBody())
+
+	_, err := client.Submit(urlscan.SubmitArguments{URL: "https://example.com/private-path"})
+	require.Error(t, err)
+
+	log := logger.String()
+	assert.Contains(t, log, "private-path")
+	assert.NotContains(t, log, "secret-api-key")
+}
+
+func TestLogrusLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	logger.SetLevel(logrus.DebugLevel)
+	client := newLoggingClient(t, "secret-api-key", urlscan.WithLogger(urlscan.NewLogrusLogger(logger)))
+
+	_, err := client.Search(urlscan.SearchArguments{})
+	require.Error(t, err)
+	assert.Contains(t, buf.String(), "Generated Query")
+	assert.Contains(t, buf.String(), "code=400")
+	assert.NotContains(t, buf.String(), "secret-api-key")
+}
+
+type sugaredLogger struct {
+	recordLogger
+}
+
+func (x *sugaredLogger) Debugw(msg string, kv ...interface{}) { x.Debug(msg, kv...) }
+func (x *sugaredLogger) Infow(msg string, kv ...interface{})  { x.Info(msg, kv...) }
+func (x *sugaredLogger) Warnw(msg string, kv ...interface{})  { x.Warn(msg, kv...) }
+func (x *sugaredLogger) Errorw(msg string, kv ...interface{}) { x.Error(msg, kv...) }
+
+func TestZapLogger(t *testing.T) {
+	sugar := &sugaredLogger{}
+	client := newLoggingClient(t, "secret-api-key", urlscan.WithLogger(urlscan.NewZapLogger(sugar)))
+
+	_, err := client.Search(urlscan.SearchArguments{})
+	require.Error(t, err)
+	assert.Contains(t, sugar.String(), "WARN")
+	assert.NotContains(t, sugar.String(), "secret-api-key")
+}
@@ -0,0 +1,126 @@
+package urlscan
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// NetworkErrorKind is a category of transport failure.
+type NetworkErrorKind int
+
+// Kinds of NetworkError.
+const (
+	NetworkErrorUnknown NetworkErrorKind = iota
+	NetworkErrorTimeout
+	NetworkErrorDNS
+	NetworkErrorTLS
+	NetworkErrorConnectionRefused
+	NetworkErrorConnectionReset
+	NetworkErrorCanceled
+)
+
+func (x NetworkErrorKind) String() string {
+	switch x {
+	case NetworkErrorTimeout:
+		return "timeout"
+	case NetworkErrorDNS:
+		return "dns"
+	case NetworkErrorTLS:
+		return "tls"
+	case NetworkErrorConnectionRefused:
+		return "connection refused"
+	case NetworkErrorConnectionReset:
+		return "connection reset"
+	case NetworkErrorCanceled:
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
+
+// NetworkError is returned when a request to urlscan.io fails without HTTP response,
+// e.g. DNS resolution failure or timeout. Original error can be retrieved by errors.Unwrap.
+type NetworkError struct {
+	Kind   NetworkErrorKind
+	Method string
+	URL    string
+	Err    error
+}
+
+func newNetworkError(req *http.Request, err error) *NetworkError {
+	return &NetworkError{
+		Kind:   classifyNetworkError(err),
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Err:    err,
+	}
+}
+
+func (x *NetworkError) Error() string {
+	return fmt.Sprintf("Fail to send urlscan.io %s request (%s): %v", x.Method, x.Kind, x.Err)
+}
+
+// Unwrap returns original error.
+func (x *NetworkError) Unwrap() error {
+	return x.Err
+}
+
+// Timeout returns true if the request timed out. It implements a part of net.Error.
+func (x *NetworkError) Timeout() bool {
+	return x.Kind == NetworkErrorTimeout
+}
+
+func classifyNetworkError(err error) NetworkErrorKind {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	var hostnameErr x509.HostnameError
+	var authorityErr x509.UnknownAuthorityError
+	var certErr x509.CertificateInvalidError
+	var recordErr tls.RecordHeaderError
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return NetworkErrorCanceled
+	case errors.As(err, &dnsErr):
+		return NetworkErrorDNS
+	case errors.As(err, &hostnameErr), errors.As(err, &authorityErr),
+		errors.As(err, &certErr), errors.As(err, &recordErr):
+		return NetworkErrorTLS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return NetworkErrorConnectionRefused
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return NetworkErrorConnectionReset
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return NetworkErrorTimeout
+	default:
+		return NetworkErrorUnknown
+	}
+}
+
+func networkErrorKind(err error) (NetworkErrorKind, bool) {
+	var netErr *NetworkError
+	if errors.As(err, &netErr) {
+		return netErr.Kind, true
+	}
+	return NetworkErrorUnknown, false
+}
+
+// IsNetworkError returns true if err is caused by transport failure, not by HTTP response.
+func IsNetworkError(err error) bool {
+	_, ok := networkErrorKind(err)
+	return ok
+}
+
+// IsTimeout returns true if err is caused by timeout of a request to urlscan.io.
+func IsTimeout(err error) bool {
+	kind, ok := networkErrorKind(err)
+	return ok && kind == NetworkErrorTimeout
+}
@@ -0,0 +1,119 @@
+package urlscan_test
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/urlscan-go/urlscan"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func requireNetworkError(t *testing.T, err error, kind urlscan.NetworkErrorKind) {
+	require.Error(t, err)
+
+	var netErr *urlscan.NetworkError
+	require.True(t, errors.As(err, &netErr), "err: %v", err)
+	assert.Equal(t, kind.String(), netErr.Kind.String(), "err: %v", err)
+	assert.True(t, urlscan.IsNetworkError(err))
+	assert.False(t, urlscan.IsNotFound(err))
+}
+
+func TestNetworkErrorTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := urlscan.NewClient("test-api-key",
+		urlscan.WithBaseURL(server.URL),
+		urlscan.WithTimeout(50*time.Millisecond),
+	)
+
+	_, err := client.Submit(urlscan.SubmitArguments{URL: "https://example.com"})
+	requireNetworkError(t, err, urlscan.NetworkErrorTimeout)
+	assert.True(t, urlscan.IsTimeout(err))
+}
+
+func TestNetworkErrorDNS(t *testing.T) {
+	// Return DNS error without real resolver not to depend on the environment.
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return nil, &net.OpError{Op: "dial", Net: network, Err: &net.DNSError{Err: "no such host", Name: "no-such-host.invalid", IsNotFound: true}}
+		},
+	}
+	client := urlscan.NewClient("test-api-key",
+		urlscan.WithBaseURL("http://no-such-host.invalid"),
+		urlscan.WithTransport(transport),
+	)
+
+	_, err := client.Search(urlscan.SearchArguments{})
+	requireNetworkError(t, err, urlscan.NetworkErrorDNS)
+}
+
+func TestNetworkErrorTLS(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	// Default http.Client does not trust certificate of the test server.
+	client := urlscan.NewClient("test-api-key", urlscan.WithBaseURL(server.URL))
+
+	task := client.ResultTask("0c5ba6ab-1234-4e72-9f4a-4d0d2bd6c0a1")
+	err := task.Get()
+	requireNetworkError(t, err, urlscan.NetworkErrorTLS)
+}
+
+func TestNetworkErrorConnectionRefused(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	client := urlscan.NewClient("test-api-key", urlscan.WithBaseURL("http://"+addr))
+
+	_, err = client.Submit(urlscan.SubmitArguments{URL: "https://example.com"})
+	requireNetworkError(t, err, urlscan.NetworkErrorConnectionRefused)
+}
+
+func TestNetworkErrorConnectionReset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if !assert.NoError(t, err) {
+			return
+		}
+		conn.Close()
+	}))
+	defer server.Close()
+
+	client := urlscan.NewClient("test-api-key", urlscan.WithBaseURL(server.URL))
+
+	_, err := client.Submit(urlscan.SubmitArguments{URL: "https://example.com"})
+	requireNetworkError(t, err, urlscan.NetworkErrorConnectionReset)
+}
+
+func TestNetworkErrorCanceled(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.SearchContext(ctx, urlscan.SearchArguments{})
+	requireNetworkError(t, err, urlscan.NetworkErrorCanceled)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
@@ -0,0 +1,235 @@
+// Package query builds search query of urlscan.io search API. The search API accepts
+// Elasticsearch query string syntax and values are escaped by builders of this package.
+//
+//	q := query.And(
+//		query.Domain("example.com"),
+//		query.Or(query.Malicious(true), query.Tag("phishing")),
+//		query.Since("now-7d"),
+//	)
+//	resp, err := client.Search(urlscan.SearchArguments{Query: urlscan.String(q.String())})
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Field names of urlscan.io search API.
+const (
+	FieldDomain     = "page.domain"
+	FieldIP         = "page.ip"
+	FieldASN        = "page.asn"
+	FieldURL        = "page.url"
+	FieldHash       = "hash"
+	FieldTags       = "task.tags"
+	FieldDate       = "date"
+	FieldMalicious  = "verdicts.malicious"
+	FieldTaskDomain = "task.domain"
+)
+
+// Query is a search query or a part of it.
+type Query interface {
+	// String returns the query string for SearchArguments.Query.
+	String() string
+}
+
+// reserved is special characters of query string syntax. They and whitespaces are escaped by backslash.
+const reserved = `+-=&|><!(){}[]^"~*?:\/`
+
+func escape(value string, keep string) string {
+	var b strings.Builder
+	for _, c := range value {
+		if (strings.ContainsRune(reserved, c) && !strings.ContainsRune(keep, c)) || unicode.IsSpace(c) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// operators are words treated as boolean operators when they appear unquoted.
+var operators = map[string]bool{"AND": true, "OR": true, "NOT": true}
+
+// value returns escaped value for a term. Empty value and operator words are quoted not to change the query.
+func value(v string, keep string) string {
+	if v == "" || operators[v] {
+		return `"` + v + `"`
+	}
+	return escape(v, keep)
+}
+
+// Raw is a query string written by hand. It is not escaped.
+type Raw string
+
+// String implements Query.
+func (x Raw) String() string {
+	return string(x)
+}
+
+type term struct {
+	field string
+	value string
+}
+
+func (x term) String() string {
+	if x.field == "" {
+		return x.value
+	}
+	return x.field + ":" + x.value
+}
+
+// Term matches documents of which field has value. Special characters in value are escaped, and empty value
+// and words of operators (AND, OR and NOT) are quoted. If field is empty, value is searched in default fields.
+func Term(field, v string) Query {
+	return term{field: field, value: value(v, "")}
+}
+
+// Phrase matches documents of which field has the exact phrase.
+func Phrase(field, value string) Query {
+	value = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+	return term{field: field, value: `"` + value + `"`}
+}
+
+// Wildcard matches documents by pattern. "*" and "?" in pattern are wildcards and other special characters are escaped.
+func Wildcard(field, pattern string) Query {
+	return term{field: field, value: value(pattern, "*?")}
+}
+
+// escapeBound escapes a bound of range. Empty and lone "*" mean unbounded. Escaped characters are unescaped by
+// the query parser, so date math (e.g. now-7d) still works.
+func escapeBound(bound string) string {
+	if bound == "" || bound == "*" {
+		return "*"
+	}
+	return value(bound, "")
+}
+
+// Range matches documents of which field is between from and to. Empty or "*" bound means unbounded.
+// includeFrom and includeTo decide if the bounds are inclusive.
+func Range(field, from, to string, includeFrom, includeTo bool) Query {
+	left, right := "{", "}"
+	if includeFrom {
+		left = "["
+	}
+	if includeTo {
+		right = "]"
+	}
+	return term{field: field, value: fmt.Sprintf("%s%s TO %s%s", left, escapeBound(from), escapeBound(to), right)}
+}
+
+// Gt matches documents of which field is greater than value.
+func Gt(field, value string) Query {
+	return term{field: field, value: ">" + escapeBound(value)}
+}
+
+// Gte matches documents of which field is greater than or equal to value.
+func Gte(field, value string) Query {
+	return term{field: field, value: ">=" + escapeBound(value)}
+}
+
+// Lt matches documents of which field is less than value.
+func Lt(field, value string) Query {
+	return term{field: field, value: "<" + escapeBound(value)}
+}
+
+// Lte matches documents of which field is less than or equal to value.
+func Lte(field, value string) Query {
+	return term{field: field, value: "<=" + escapeBound(value)}
+}
+
+type group struct {
+	op      string
+	queries []Query
+}
+
+func (x group) String() string {
+	var parts []string
+	for _, q := range x.queries {
+		if q == nil {
+			continue
+		}
+		if s := q.String(); s != "" {
+			parts = append(parts, s)
+		}
+	}
+
+	switch len(parts) {
+	case 0:
+		return ""
+	case 1:
+		return parts[0]
+	default:
+		return "(" + strings.Join(parts, " "+x.op+" ") + ")"
+	}
+}
+
+// And matches documents matched by all queries.
+func And(queries ...Query) Query {
+	return group{op: "AND", queries: queries}
+}
+
+// Or matches documents matched by any of queries.
+func Or(queries ...Query) Query {
+	return group{op: "OR", queries: queries}
+}
+
+type not struct {
+	query Query
+}
+
+func (x not) String() string {
+	if x.query == nil {
+		return ""
+	}
+	s := x.query.String()
+	if s == "" {
+		return ""
+	}
+	return "NOT " + s
+}
+
+// Not matches documents not matched by q.
+func Not(q Query) Query {
+	return not{query: q}
+}
+
+// Domain matches page.domain. Use Wildcard(FieldDomain, "*.example.com") for subdomains.
+func Domain(domain string) Query {
+	return Term(FieldDomain, domain)
+}
+
+// IP matches page.ip. CIDR notation such as "192.0.2.0/24" is also available.
+func IP(ip string) Query {
+	return Term(FieldIP, ip)
+}
+
+// ASN matches page.asn, e.g. "AS15169".
+func ASN(asn string) Query {
+	return Term(FieldASN, asn)
+}
+
+// Hash matches SHA256 hash of any resource in the page.
+func Hash(hash string) Query {
+	return Term(FieldHash, hash)
+}
+
+// Tag matches task.tags given by submitter.
+func Tag(tag string) Query {
+	return Term(FieldTags, tag)
+}
+
+// Malicious matches verdicts.malicious.
+func Malicious(malicious bool) Query {
+	return term{field: FieldMalicious, value: fmt.Sprint(malicious)}
+}
+
+// Since matches scans after date, e.g. "now-7d" or "2020-01-01".
+func Since(date string) Query {
+	return Gt(FieldDate, date)
+}
+
+// Between matches scans from date "from" to date "to" inclusive.
+func Between(from, to string) Query {
+	return Range(FieldDate, from, to, true, true)
+}
@@ -0,0 +1,52 @@
+package query_test
+
+import (
+	"testing"
+
+	"github.com/m-mizutani/urlscan-go/urlscan/query"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery(t *testing.T) {
+	testCases := []struct {
+		title string
+		query query.Query
+		expr  string
+	}{
+		{"domain", query.Domain("example.com"), `page.domain:example.com`},
+		{"escape", query.Term("page.url", "https://example.com/a b?x=(1)"), `page.url:https\:\/\/example.com\/a\ b\?x\=\(1\)`},
+		{"ip cidr", query.IP("192.0.2.0/24"), `page.ip:192.0.2.0\/24`},
+		{"phrase", query.Phrase("page.title", `say "hello"`), `page.title:"say \"hello\""`},
+		{"wildcard", query.Wildcard(query.FieldDomain, "*.example-1.com"), `page.domain:*.example\-1.com`},
+		{"range", query.Range("page.tlsValidDays", "1", "", true, false), `page.tlsValidDays:[1 TO *}`},
+		{"since", query.Since("now-7d"), `date:>now\-7d`},
+		{"between", query.Between("2020-01-01", "2020-01-31"), `date:[2020\-01\-01 TO 2020\-01\-31]`},
+		{"whitespace", query.Term("page.title", "a\nb\rc\td\u3000e"), "page.title:a\\\nb\\\rc\\\td\\\u3000e"},
+		{"whitespace wildcard", query.Wildcard("page.title", "a\n*"), "page.title:a\\\n*"},
+		{"whitespace bound", query.Lt("page.title", "a\nb"), "page.title:<a\\\nb"},
+		{"empty term", query.Term("page.title", ""), `page.title:""`},
+		{"empty wildcard", query.Wildcard("page.title", ""), `page.title:""`},
+		{"operator term", query.Term("page.title", "AND"), `page.title:"AND"`},
+		{"operator tag", query.Tag("OR"), `task.tags:"OR"`},
+		{"operator default field", query.Term("", "NOT"), `"NOT"`},
+		{"lowercase operator", query.Term("page.title", "and"), `page.title:and`},
+		{"escape bound", query.Range("page.title", "a+b:c/d", "x|y>z=!&?^~", false, false),
+			`page.title:{a\+b\:c\/d TO x\|y\>z\=\!\&\?\^\~}`},
+		{"escape bound wildcard", query.Lt("page.title", "a*"), `page.title:<a\*`},
+		{"operator bound", query.Gte("page.title", "OR"), `page.title:>="OR"`},
+		{"malicious", query.Malicious(true), `verdicts.malicious:true`},
+		{"and or", query.And(query.ASN("AS15169"), query.Or(query.Hash("abc"), query.Tag("phishing"))),
+			`(page.asn:AS15169 AND (hash:abc OR task.tags:phishing))`},
+		{"not", query.And(query.Domain("example.com"), query.Not(query.IP("192.0.2.1"))),
+			`(page.domain:example.com AND NOT page.ip:192.0.2.1)`},
+		{"single", query.Or(query.Domain("example.com")), `page.domain:example.com`},
+		{"empty", query.And(nil, query.Or(), query.Not(nil)), ``},
+		{"raw", query.And(query.Raw("filename:*.exe"), query.Domain("example.com")), `(filename:*.exe AND page.domain:example.com)`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			assert.Equal(t, tc.expr, tc.query.String())
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package urlscan
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Queue records submitted scans into Store and resumes polling of unfinished scans, e.g. after restart of the process.
+type Queue struct {
+	client *Client
+	store  Store
+}
+
+// NewQueue is a constructor of Queue.
+func NewQueue(client *Client, store Store) *Queue {
+	return &Queue{
+		client: client,
+		store:  store,
+	}
+}
+
+// Submit submits a scan and records it into Store. If the scan is submitted but recording failed,
+// it returns the Task with an error.
+func (x *Queue) Submit(ctx context.Context, args SubmitArguments) (Task, error) {
+	task, err := x.client.SubmitContext(ctx, args)
+	if err != nil {
+		return task, err
+	}
+
+	if err := x.Add(&task, args.URL); err != nil {
+		return task, err
+	}
+	return task, nil
+}
+
+// Add records a Task already submitted, e.g. by BulkSubmitter. url is the submitted URL.
+func (x *Queue) Add(task *Task, url string) error {
+	entry := QueueEntry{
+		UUID:        task.UUID(),
+		URL:         url,
+		ResultURL:   task.ResultURL(),
+		APIURL:      task.APIURL(),
+		Visibility:  task.Visibility(),
+		SubmittedAt: task.SubmittedAt(),
+		Status:      TaskStatusPending,
+	}
+	if entry.SubmittedAt.IsZero() {
+		entry.SubmittedAt = time.Now()
+	}
+
+	if err := x.store.Put(entry); err != nil {
+		return errors.Wrapf(err, "Fail to record task: %s", entry.UUID)
+	}
+	return nil
+}
+
+// Pending returns entries of which scan is not finished yet.
+func (x *Queue) Pending() ([]QueueEntry, error) {
+	entries, err := x.store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []QueueEntry
+	for _, entry := range entries {
+		if !entry.Status.Finished() {
+			pending = append(pending, entry)
+		}
+	}
+	return pending, nil
+}
+
+// Complete records final status of a scan. err is the error returned by Await, if any.
+func (x *Queue) Complete(entry QueueEntry, status TaskStatus, err error) error {
+	entry.Status = status
+	entry.CompletedAt = time.Now()
+	entry.Error = ""
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	if err := x.store.Put(entry); err != nil {
+		return errors.Wrapf(err, "Fail to record completion of task: %s", entry.UUID)
+	}
+	return nil
+}
+
+// Resume polls all pending scans in Store by WaitAll manner and records each finished scan as soon as it finishes.
+// Scans that are not finished by timeout or ctx remain pending and are polled again by next Resume.
+// Returned results are in order of Pending().
+func (x *Queue) Resume(ctx context.Context, opts BatchOptions) ([]BatchResult, error) {
+	entries, err := x.Pending()
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]*Task, len(entries))
+	for i, entry := range entries {
+		task := x.client.ResultTask(entry.UUID)
+		tasks[i] = &task
+	}
+
+	ch := make(chan BatchResult, len(tasks))
+	go runBatch(ctx, tasks, opts, ch)
+
+	results := make([]BatchResult, len(tasks))
+	var storeErr error
+	for res := range ch {
+		results[res.Index] = res
+		if !res.Result.Status.Finished() {
+			continue
+		}
+
+		if err := x.Complete(entries[res.Index], res.Result.Status, res.Err); err != nil && storeErr == nil {
+			storeErr = err
+		}
+	}
+
+	if storeErr != nil {
+		return results, storeErr
+	}
+	return results, interrupted(ctx, results)
+}
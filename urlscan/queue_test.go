@@ -0,0 +1,180 @@
+package urlscan_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/urlscan-go/urlscan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStore(t *testing.T, store urlscan.Store) {
+	entries, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(entries))
+
+	require.NoError(t, store.Put(urlscan.QueueEntry{UUID: "a", URL: "https://a.example.com", Status: urlscan.TaskStatusPending}))
+	require.NoError(t, store.Put(urlscan.QueueEntry{UUID: "b", URL: "https://b.example.com", Status: urlscan.TaskStatusPending}))
+	require.NoError(t, store.Put(urlscan.QueueEntry{UUID: "a", URL: "https://a.example.com", Status: urlscan.TaskStatusDone}))
+
+	entries, err = store.Load()
+	require.NoError(t, err)
+	require.Equal(t, 2, len(entries))
+	assert.Equal(t, "a", entries[0].UUID)
+	assert.Equal(t, urlscan.TaskStatusDone, entries[0].Status)
+	assert.Equal(t, "b", entries[1].UUID)
+	assert.Equal(t, urlscan.TaskStatusPending, entries[1].Status)
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, urlscan.NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "urlscan-go")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	testStore(t, urlscan.NewFileStore(filepath.Join(dir, "queue.jsonl")))
+}
+
+func TestFileStoreBrokenLine(t *testing.T) {
+	dir, err := ioutil.TempDir("", "urlscan-go")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "queue.jsonl")
+	store := urlscan.NewFileStore(path)
+	require.NoError(t, store.Put(urlscan.QueueEntry{UUID: "a", Status: urlscan.TaskStatusPending}))
+
+	// Simulate crash while writing.
+	fd, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	require.NoError(t, err)
+	fd.Write([]byte(`{"uuid":"b","sta`))
+	fd.Close()
+
+	entries, err := store.Load()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(entries))
+
+	require.NoError(t, store.Put(urlscan.QueueEntry{UUID: "c", Status: urlscan.TaskStatusPending}))
+	entries, err = store.Load()
+	require.NoError(t, err)
+	require.Equal(t, 2, len(entries))
+	assert.Equal(t, "c", entries[1].UUID)
+
+	raw, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), `"b"`)
+
+	// Put after crash discards the broken line not to leave it in the middle.
+	fd, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	require.NoError(t, err)
+	fd.Write([]byte(`{"uuid":"d","sta`))
+	fd.Close()
+
+	require.NoError(t, store.Put(urlscan.QueueEntry{UUID: "e", Status: urlscan.TaskStatusPending}))
+	entries, err = store.Load()
+	require.NoError(t, err)
+	require.Equal(t, 3, len(entries))
+	assert.Equal(t, "e", entries[2].UUID)
+}
+
+func TestFileStoreCorruptedLine(t *testing.T) {
+	dir, err := ioutil.TempDir("", "urlscan-go")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "queue.jsonl")
+	data := `{"uuid":"a","status":"pending"}` + "\n" + `{"uuid":"b",` + "\n" + `{"uuid":"c","status":"pending"}` + "\n"
+	require.NoError(t, ioutil.WriteFile(path, []byte(data), 0600))
+
+	_, err = urlscan.NewFileStore(path).Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+}
+
+func TestFileStoreCompaction(t *testing.T) {
+	dir, err := ioutil.TempDir("", "urlscan-go")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "queue.jsonl")
+	store := urlscan.NewFileStore(path)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, store.Put(urlscan.QueueEntry{UUID: "a", Status: urlscan.TaskStatusPending}))
+	}
+	require.NoError(t, store.Put(urlscan.QueueEntry{UUID: "b", Status: urlscan.TaskStatusDone}))
+
+	entries, err := store.Load()
+	require.NoError(t, err)
+	require.Equal(t, 2, len(entries))
+
+	raw, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(raw), "\n"))
+
+	entries, err = store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(entries))
+}
+
+func TestQueueResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "urlscan-go")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "queue.jsonl")
+
+	var active, maxActive int32
+	client := newBatchClient(t, &active, &maxActive)
+
+	// First process submits scans and crashes before polling.
+	queue := urlscan.NewQueue(&client, urlscan.NewFileStore(path))
+	task, err := queue.Submit(context.Background(), urlscan.SubmitArguments{URL: "https://example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "done-1", task.UUID())
+
+	for _, uuid := range []string{"deleted-1", "pending-1"} {
+		task := client.ResultTask(uuid)
+		require.NoError(t, queue.Add(&task, "https://"+uuid+".example.com"))
+	}
+
+	// Second process resumes polling.
+	queue = urlscan.NewQueue(&client, urlscan.NewFileStore(path))
+	pending, err := queue.Pending()
+	require.NoError(t, err)
+	require.Equal(t, 3, len(pending))
+	assert.Equal(t, "https://example.com", pending[0].URL)
+	assert.Equal(t, "https://urlscan.io/result/done-1/", pending[0].ResultURL)
+
+	results, err := queue.Resume(context.Background(), urlscan.BatchOptions{
+		WaitOptions: []urlscan.WaitOption{
+			urlscan.WaitMaxRetry(2),
+			urlscan.WaitBackoff(urlscan.ConstantBackoff{Interval: time.Millisecond}),
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, len(results))
+	assert.Equal(t, urlscan.TaskStatusDone, results[0].Result.Status)
+	assert.Equal(t, "done-1", results[0].Task.Result.Task.UUID)
+	assert.Equal(t, urlscan.TaskStatusDeleted, results[1].Result.Status)
+	assert.Equal(t, urlscan.TaskStatusPending, results[2].Result.Status)
+
+	// Only unfinished scan remains.
+	pending, err = queue.Pending()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(pending))
+	assert.Equal(t, "pending-1", pending[0].UUID)
+
+	entries, err := urlscan.NewFileStore(path).Load()
+	require.NoError(t, err)
+	require.Equal(t, 3, len(entries))
+	assert.False(t, entries[0].CompletedAt.IsZero())
+	assert.Contains(t, entries[1].Error, "deleted")
+}
@@ -0,0 +1,159 @@
+package urlscan
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Rate limited actions reported by X-Rate-Limit-Action header of urlscan.io. Scan has separated budget for each visibility.
+const (
+	RateLimitActionPublic   = "public"
+	RateLimitActionUnlisted = "unlisted"
+	RateLimitActionPrivate  = "private"
+	RateLimitActionRetrieve = "retrieve"
+	RateLimitActionSearch   = "search"
+)
+
+// Endpoints used as rate limited action when urlscan.io does not return X-Rate-Limit-Action header.
+const (
+	RateLimitActionScan   = "scan"
+	RateLimitActionResult = "result"
+)
+
+// rateTarget identifies rate limit budget used by a request. expected is X-Rate-Limit-Action that the request is expected
+// to consume, or empty if it is not known before the response (e.g. scan with account default visibility).
+// path is the endpoint, used when the header is missing.
+type rateTarget struct {
+	path     string
+	expected string
+}
+
+// defaultRateLimitDelay is used to wait after 429 response without Retry-After and X-Rate-Limit-Reset headers.
+const defaultRateLimitDelay = time.Second
+
+// rateLimiter keeps latest rate limit status for each action. It is shared by copies of a Client and safe for concurrent use.
+type rateLimiter struct {
+	mutex  sync.Mutex
+	limits map[string]RateLimit
+	// latest is the last action reported for each endpoint.
+	latest map[string]string
+
+	// wait makes requests block until reset when remaining budget of the action is zero.
+	wait bool
+	// maxRetry is a number of retries for 429 response.
+	maxRetry int
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		limits: make(map[string]RateLimit),
+		latest: make(map[string]string),
+	}
+}
+
+// update saves rate limit of the response by X-Rate-Limit-Action header, or by endpoint if the header is missing.
+func (x *rateLimiter) update(target rateTarget, header http.Header) {
+	rl, ok := parseRateLimit(header)
+	if !ok {
+		return
+	}
+
+	key := rl.Action
+	if key == "" {
+		key = target.path
+	}
+
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	x.limits[key] = rl
+	x.latest[target.path] = key
+}
+
+// lookup returns rate limit of the expected action. If the action is not known, rate limit of the action reported
+// last for the endpoint is returned.
+func (x *rateLimiter) lookup(target rateTarget) (RateLimit, bool) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	if target.expected != "" {
+		rl, ok := x.limits[target.expected]
+		return rl, ok
+	}
+	if key, ok := x.latest[target.path]; ok {
+		rl, ok := x.limits[key]
+		return rl, ok
+	}
+	return RateLimit{}, false
+}
+
+func (x *rateLimiter) snapshot() map[string]RateLimit {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	limits := make(map[string]RateLimit, len(x.limits))
+	for action, rl := range x.limits {
+		limits[action] = rl
+	}
+	return limits
+}
+
+// resetDelay returns how long to wait until the budget of target is reset. It returns 0 if some requests are still remaining.
+func (x *rateLimiter) resetDelay(target rateTarget) time.Duration {
+	rl, ok := x.lookup(target)
+	if !ok || rl.Limit == 0 || rl.Remaining > 0 {
+		return 0
+	}
+
+	if d := time.Until(rl.Reset); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// budgetDelay returns how long a request of target should wait until the budget is reset.
+// It returns 0 if some requests are still remaining or waiting is disabled.
+func (x *rateLimiter) budgetDelay(target rateTarget) time.Duration {
+	if !x.wait {
+		return 0
+	}
+	return x.resetDelay(target)
+}
+
+// retryDelay returns how long it should wait before retrying a request that got 429.
+func retryDelay(rl RateLimit) time.Duration {
+	if rl.RetryAfter > 0 {
+		return rl.RetryAfter
+	}
+	if d := time.Until(rl.Reset); d > 0 {
+		return d
+	}
+	return defaultRateLimitDelay
+}
+
+// WithRateLimitWait makes the Client block a request until the rate limit window is reset
+// when X-Rate-Limit-Remaining of the previous response for the same action is zero.
+func WithRateLimitWait() Option {
+	return func(x *Client) {
+		x.rateLimiter.wait = true
+	}
+}
+
+// WithRateLimitRetry makes the Client retry a request up to maxRetry times when urlscan.io responds 429.
+// It waits for Retry-After (or X-Rate-Limit-Reset) before each retry.
+func WithRateLimitRetry(maxRetry int) Option {
+	return func(x *Client) {
+		x.rateLimiter.maxRetry = maxRetry
+	}
+}
+
+// RateLimits returns latest rate limit status of each action reported by X-Rate-Limit-Action header, such as
+// RateLimitActionPublic, RateLimitActionRetrieve and RateLimitActionSearch. If urlscan.io does not return the header,
+// the status is keyed by endpoint (RateLimitActionScan, RateLimitActionSearch and RateLimitActionResult).
+// Actions that have not been requested yet are not included.
+func (x Client) RateLimits() map[string]RateLimit {
+	if x.rateLimiter == nil {
+		return map[string]RateLimit{}
+	}
+	return x.rateLimiter.snapshot()
+}
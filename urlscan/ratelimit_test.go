@@ -0,0 +1,148 @@
+package urlscan_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/urlscan-go/urlscan"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimits(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Rate-Limit-Action", "search")
+		w.Header().Set("X-Rate-Limit-Window", "minute")
+		w.Header().Set("X-Rate-Limit-Limit", "120")
+		w.Header().Set("X-Rate-Limit-Remaining", "119")
+		w.Write([]byte(`{"results":[],"total":0}`))
+	}))
+
+	assert.Equal(t, 0, len(client.RateLimits()))
+
+	_, err := client.Search(urlscan.SearchArguments{})
+	require.NoError(t, err)
+
+	limits := client.RateLimits()
+	require.Contains(t, limits, urlscan.RateLimitActionSearch)
+	assert.Equal(t, int64(120), limits[urlscan.RateLimitActionSearch].Limit)
+	assert.Equal(t, int64(119), limits[urlscan.RateLimitActionSearch].Remaining)
+	assert.NotContains(t, limits, urlscan.RateLimitActionScan)
+}
+
+func TestRateLimitRetry(t *testing.T) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&count, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.Header().Set("X-Rate-Limit-Reset-After", "0.1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"message":"Rate limit for this action exceeded","status":429}`))
+			return
+		}
+		w.Write([]byte(`{"uuid":"0c5ba6ab-1234-4e72-9f4a-4d0d2bd6c0a1"}`))
+	}))
+	defer server.Close()
+
+	client := urlscan.NewClient("test-api-key",
+		urlscan.WithBaseURL(server.URL),
+		urlscan.WithRateLimitRetry(1),
+	)
+
+	_, err := client.Submit(urlscan.SubmitArguments{URL: "https://example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&count))
+}
+
+func TestRateLimitRetryExhausted(t *testing.T) {
+	var count int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+
+	_, err := client.Submit(urlscan.SubmitArguments{URL: "https://example.com"})
+	assert.True(t, urlscan.IsRateLimited(err))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&count))
+}
+
+func TestRateLimitWait(t *testing.T) {
+	reset := time.Now().Add(300 * time.Millisecond)
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		w.Header().Set("X-Rate-Limit-Limit", "1")
+		w.Header().Set("X-Rate-Limit-Remaining", "0")
+		w.Header().Set("X-Rate-Limit-Reset", reset.UTC().Format(time.RFC3339Nano))
+		w.Write([]byte(`{"results":[],"total":0}`))
+	}))
+	defer server.Close()
+
+	client := urlscan.NewClient("test-api-key",
+		urlscan.WithBaseURL(server.URL),
+		urlscan.WithRateLimitWait(),
+	)
+
+	_, err := client.Search(urlscan.SearchArguments{})
+	require.NoError(t, err)
+	_, err = client.Search(urlscan.SearchArguments{})
+	require.NoError(t, err)
+	assert.False(t, time.Now().Before(reset))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&count))
+}
+
+func TestRateLimitsByAction(t *testing.T) {
+	reset := time.Now().Add(time.Hour)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var args urlscan.SubmitArguments
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&args))
+
+		w.Header().Set("X-Rate-Limit-Action", string(args.Visibility))
+		w.Header().Set("X-Rate-Limit-Limit", "1")
+		w.Header().Set("X-Rate-Limit-Remaining", "0")
+		w.Header().Set("X-Rate-Limit-Reset", reset.UTC().Format(time.RFC3339Nano))
+		w.Write([]byte(`{"uuid":"0c5ba6ab-1234-4e72-9f4a-4d0d2bd6c0a1"}`))
+	}))
+	defer server.Close()
+
+	client := urlscan.NewClient("test-api-key", urlscan.WithBaseURL(server.URL), urlscan.WithRateLimitWait())
+
+	_, err := client.Submit(urlscan.SubmitArguments{URL: "https://example.com", Visibility: urlscan.VisibilityPublic})
+	require.NoError(t, err)
+
+	limits := client.RateLimits()
+	require.Contains(t, limits, urlscan.RateLimitActionPublic)
+	assert.NotContains(t, limits, urlscan.RateLimitActionPrivate)
+	assert.NotContains(t, limits, urlscan.RateLimitActionScan)
+
+	// Exhausted public budget does not block private scan.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = client.SubmitContext(ctx, urlscan.SubmitArguments{URL: "https://example.com", Visibility: urlscan.VisibilityPrivate})
+	require.NoError(t, err)
+	assert.Contains(t, client.RateLimits(), urlscan.RateLimitActionPrivate)
+
+	// Public scan waits for reset of the public budget.
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = client.SubmitContext(ctx, urlscan.SubmitArguments{URL: "https://example.com", Visibility: urlscan.VisibilityPublic})
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestRateLimitsWithoutActionHeader(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Rate-Limit-Limit", "120")
+		w.Header().Set("X-Rate-Limit-Remaining", "119")
+		w.Write([]byte(`{"task":{"uuid":"0c5ba6ab-1234-4e72-9f4a-4d0d2bd6c0a1"}}`))
+	}))
+
+	task := client.ResultTask("0c5ba6ab-1234-4e72-9f4a-4d0d2bd6c0a1")
+	require.NoError(t, task.Get())
+	assert.Contains(t, client.RateLimits(), urlscan.RateLimitActionResult)
+}
@@ -0,0 +1,149 @@
+package urlscan
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RetryAttempt describes a result of a single HTTP request to urlscan.io.
+type RetryAttempt struct {
+	// Method and URL of the request.
+	Method string
+	URL    string
+	// Attempt is 1 for the first request and incremented by each retry.
+	Attempt int
+	// StatusCode is 0 if no response is received.
+	StatusCode int
+	// Err is nil if the request succeeded.
+	Err error
+	// Idempotent is true if sending the request twice is safe. POST /scan is not idempotent
+	// because it may submit the same URL twice and consume scan quota.
+	Idempotent bool
+
+	// Retrying and Delay are decided by RetryPolicy and available only in hook of WithRetryHook.
+	Retrying bool
+	Delay    time.Duration
+}
+
+// RetryPolicy decides if a failed request should be retried and how long to wait before that.
+type RetryPolicy interface {
+	Retry(attempt RetryAttempt) (time.Duration, bool)
+}
+
+// Default values of ExponentialRetryPolicy.
+const (
+	DefaultRetryMaxAttempts = 3
+	DefaultRetryBaseDelay   = 500 * time.Millisecond
+	DefaultRetryMaxDelay    = 10 * time.Second
+)
+
+// DefaultRetryableStatus is a set of status code retried by ExponentialRetryPolicy.
+var DefaultRetryableStatus = []int{
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// ExponentialRetryPolicy retries transport errors and retryable status codes with exponential backoff and jitter.
+// Zero value fields are replaced with default values.
+type ExponentialRetryPolicy struct {
+	// MaxAttempts is maximum number of requests including the first one.
+	MaxAttempts int
+	// BaseDelay is a delay before the first retry. It is doubled by each retry up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// RetryableStatus is a set of status code to be retried. Default is DefaultRetryableStatus.
+	RetryableStatus []int
+	// RetryNonIdempotent allows retrying non idempotent request (POST /scan) even if the request may have reached urlscan.io.
+	// By default, such a request is retried only when connection to urlscan.io could not be established.
+	RetryNonIdempotent bool
+}
+
+// Retry implements RetryPolicy.
+func (x ExponentialRetryPolicy) Retry(attempt RetryAttempt) (time.Duration, bool) {
+	maxAttempts := x.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = DefaultRetryMaxAttempts
+	}
+	if attempt.Err == nil || attempt.Attempt >= maxAttempts {
+		return 0, false
+	}
+
+	if !x.retryable(attempt) {
+		return 0, false
+	}
+
+	return x.delay(attempt.Attempt), true
+}
+
+func (x ExponentialRetryPolicy) retryable(attempt RetryAttempt) bool {
+	// Connection was not established, then the request never reached urlscan.io.
+	if isDialError(attempt.Err) {
+		return true
+	}
+	if !attempt.Idempotent && !x.RetryNonIdempotent {
+		return false
+	}
+
+	if kind, ok := networkErrorKind(attempt.Err); ok {
+		// Certificate problem and cancellation are not solved by retry.
+		return kind != NetworkErrorTLS && kind != NetworkErrorCanceled
+	}
+
+	retryableStatus := x.RetryableStatus
+	if retryableStatus == nil {
+		retryableStatus = DefaultRetryableStatus
+	}
+	for _, code := range retryableStatus {
+		if attempt.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (x ExponentialRetryPolicy) delay(attempt int) time.Duration {
+	backoff := ExponentialBackoff{
+		Initial: x.BaseDelay,
+		Max:     x.MaxDelay,
+		// Equal jitter: half of the delay is fixed and another half is random.
+		Jitter: 0.5,
+	}
+	if backoff.Initial == 0 {
+		backoff.Initial = DefaultRetryBaseDelay
+	}
+	if backoff.Max == 0 {
+		backoff.Max = DefaultRetryMaxDelay
+	}
+
+	return backoff.Delay(attempt)
+}
+
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// WithRetryPolicy sets RetryPolicy applied to requests of all endpoints. By default, no request is retried.
+// 429 response is controlled by WithRateLimitRetry instead.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(x *Client) {
+		x.retryPolicy = policy
+	}
+}
+
+// WithRetryHook sets a function called after every request to urlscan.io with its result and retry decision.
+func WithRetryHook(hook func(RetryAttempt)) Option {
+	return func(x *Client) {
+		x.retryHook = hook
+	}
+}
@@ -0,0 +1,180 @@
+package urlscan_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/urlscan-go/urlscan"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRetryPolicy = urlscan.ExponentialRetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   time.Millisecond,
+	MaxDelay:    10 * time.Millisecond,
+}
+
+func newFlakyServer(t *testing.T, failures int32, code int) (*httptest.Server, *int32) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&count, 1) <= failures {
+			w.WriteHeader(code)
+			return
+		}
+		w.Write([]byte(`{"results":[],"total":0,"uuid":"0c5ba6ab-1234-4e72-9f4a-4d0d2bd6c0a1"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &count
+}
+
+func TestRetryPolicyRetriesGet(t *testing.T) {
+	server, count := newFlakyServer(t, 2, http.StatusBadGateway)
+
+	var attempts []urlscan.RetryAttempt
+	client := urlscan.NewClient("test-api-key",
+		urlscan.WithBaseURL(server.URL),
+		urlscan.WithRetryPolicy(testRetryPolicy),
+		urlscan.WithRetryHook(func(attempt urlscan.RetryAttempt) {
+			attempts = append(attempts, attempt)
+		}),
+	)
+
+	_, err := client.Search(urlscan.SearchArguments{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(count))
+
+	require.Equal(t, 3, len(attempts))
+	assert.Equal(t, 1, attempts[0].Attempt)
+	assert.Equal(t, http.StatusBadGateway, attempts[0].StatusCode)
+	assert.True(t, attempts[0].Retrying)
+	assert.True(t, attempts[0].Idempotent)
+	assert.Equal(t, 3, attempts[2].Attempt)
+	assert.Equal(t, http.StatusOK, attempts[2].StatusCode)
+	assert.False(t, attempts[2].Retrying)
+}
+
+func TestRetryPolicyMaxAttempts(t *testing.T) {
+	server, count := newFlakyServer(t, 10, http.StatusServiceUnavailable)
+	client := urlscan.NewClient("test-api-key",
+		urlscan.WithBaseURL(server.URL),
+		urlscan.WithRetryPolicy(testRetryPolicy),
+	)
+
+	_, err := client.Search(urlscan.SearchArguments{})
+	require.Error(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(count))
+}
+
+func TestRetryPolicyDoesNotRetryNonRetryableStatus(t *testing.T) {
+	server, count := newFlakyServer(t, 10, http.StatusBadRequest)
+	client := urlscan.NewClient("test-api-key",
+		urlscan.WithBaseURL(server.URL),
+		urlscan.WithRetryPolicy(testRetryPolicy),
+	)
+
+	_, err := client.Search(urlscan.SearchArguments{})
+	require.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(count))
+}
+
+func TestRetryPolicySubmitIsNotRetriedByDefault(t *testing.T) {
+	server, count := newFlakyServer(t, 1, http.StatusBadGateway)
+	client := urlscan.NewClient("test-api-key",
+		urlscan.WithBaseURL(server.URL),
+		urlscan.WithRetryPolicy(testRetryPolicy),
+	)
+
+	_, err := client.Submit(urlscan.SubmitArguments{URL: "https://example.com"})
+	require.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(count))
+}
+
+func TestRetryPolicySubmitRetryNonIdempotent(t *testing.T) {
+	server, count := newFlakyServer(t, 1, http.StatusBadGateway)
+	policy := testRetryPolicy
+	policy.RetryNonIdempotent = true
+	client := urlscan.NewClient("test-api-key",
+		urlscan.WithBaseURL(server.URL),
+		urlscan.WithRetryPolicy(policy),
+	)
+
+	task, err := client.Submit(urlscan.SubmitArguments{URL: "https://example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(count))
+	assert.NotNil(t, task)
+}
+
+func TestRetryPolicySubmitRetriedOnDialError(t *testing.T) {
+	// Reserve a port and close it to make connection refused.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	var attempts int
+	client := urlscan.NewClient("test-api-key",
+		urlscan.WithBaseURL("http://"+addr),
+		urlscan.WithRetryPolicy(testRetryPolicy),
+		urlscan.WithRetryHook(func(attempt urlscan.RetryAttempt) { attempts++ }),
+	)
+
+	_, err = client.Submit(urlscan.SubmitArguments{URL: "https://example.com"})
+	require.Error(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func newSlowOnceServer(t *testing.T) (*httptest.Server, *int32) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&count, 1) == 1 {
+			time.Sleep(300 * time.Millisecond)
+		}
+		w.Write([]byte(`{"results":[],"total":0,"uuid":"0c5ba6ab-1234-4e72-9f4a-4d0d2bd6c0a1"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &count
+}
+
+func TestRetryPolicyRetriesClientTimeout(t *testing.T) {
+	server, count := newSlowOnceServer(t)
+
+	var attempts []urlscan.RetryAttempt
+	client := urlscan.NewClient("test-api-key",
+		urlscan.WithBaseURL(server.URL),
+		urlscan.WithTimeout(100*time.Millisecond),
+		urlscan.WithRetryPolicy(testRetryPolicy),
+		urlscan.WithRetryHook(func(attempt urlscan.RetryAttempt) {
+			attempts = append(attempts, attempt)
+		}),
+	)
+
+	_, err := client.Search(urlscan.SearchArguments{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(count))
+	require.Equal(t, 2, len(attempts))
+	assert.True(t, urlscan.IsTimeout(attempts[0].Err))
+	assert.True(t, attempts[0].Retrying)
+}
+
+func TestRetryPolicyDoesNotRetryContextDeadline(t *testing.T) {
+	server, count := newSlowOnceServer(t)
+
+	client := urlscan.NewClient("test-api-key",
+		urlscan.WithBaseURL(server.URL),
+		urlscan.WithRetryPolicy(testRetryPolicy),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := client.SearchContext(ctx, urlscan.SearchArguments{})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, int32(1), atomic.LoadInt32(count))
+}
@@ -1,12 +1,40 @@
 package urlscan
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 )
 
+// Visibility is visibility of a scan result.
+type Visibility string
+
+// Visibilities accepted by urlscan.io.
+const (
+	VisibilityPublic   Visibility = "public"
+	VisibilityUnlisted Visibility = "unlisted"
+	VisibilityPrivate  Visibility = "private"
+)
+
+// Valid returns true if x is a known visibility.
+func (x Visibility) Valid() bool {
+	switch x {
+	case VisibilityPublic, VisibilityUnlisted, VisibilityPrivate:
+		return true
+	}
+	return false
+}
+
+// MaxSubmitTags is maximum number of tags for a scan.
+const MaxSubmitTags = 10
+
+// ErrInvalidArguments is returned (wrapped) by Submit when SubmitArguments is invalid. The request is not sent in that case.
+var ErrInvalidArguments = errors.New("Invalid arguments")
+
 // SubmitArguments is input argument of Submit()
 type SubmitArguments struct {
 	// URL is Required.
@@ -16,104 +44,319 @@ type SubmitArguments struct {
 	// Referer is optional. You can set any Referer.
 	Referer *string `json:"referer"`
 	// Public is optional. Default is "off" that means "private". You need to set it as "on" if you want to make the result public.
+	//
+	// Deprecated: Use Visibility instead.
 	Public *string `json:"public"`
+	// Visibility is optional. Default is determined by your account setting.
+	Visibility Visibility `json:"visibility,omitempty"`
+	// Tags is optional. User defined tags to annotate the scan, up to MaxSubmitTags.
+	Tags []string `json:"tags,omitempty"`
+	// Country is optional. ISO 3166-1 alpha-2 country code (e.g. "de") to scan from the country.
+	Country *string `json:"country,omitempty"`
+	// OverrideSafety is optional. If true, urlscan.io does not reclassify the visibility of the scan even if the URL looks sensitive.
+	OverrideSafety *bool `json:"overrideSafety,omitempty"`
+}
+
+// rateTarget returns rate limit target of the scan. Action is unknown if visibility is default of the account.
+func (x SubmitArguments) rateTarget() rateTarget {
+	target := rateTarget{path: RateLimitActionScan}
+	switch {
+	case x.Visibility != "":
+		target.expected = string(x.Visibility)
+	case x.Public != nil && *x.Public == "on":
+		target.expected = RateLimitActionPublic
+	case x.Public != nil:
+		target.expected = RateLimitActionPrivate
+	}
+	return target
+}
+
+// Validate checks SubmitArguments before sending it to urlscan.io.
+func (x SubmitArguments) Validate() error {
+	if x.URL == "" {
+		return errors.Wrap(ErrInvalidArguments, "URL is required")
+	}
+	if x.Public != nil && *x.Public != "on" && *x.Public != "off" {
+		return errors.Wrapf(ErrInvalidArguments, "Public must be \"on\" or \"off\", got %q", *x.Public)
+	}
+	if x.Public != nil && x.Visibility != "" {
+		return errors.Wrap(ErrInvalidArguments, "Public and Visibility can not be set together")
+	}
+	if x.Visibility != "" && !x.Visibility.Valid() {
+		return errors.Wrapf(ErrInvalidArguments, "Unknown Visibility %q", x.Visibility)
+	}
+	if len(x.Tags) > MaxSubmitTags {
+		return errors.Wrapf(ErrInvalidArguments, "Too many Tags, %d > %d", len(x.Tags), MaxSubmitTags)
+	}
+	for _, tag := range x.Tags {
+		if strings.TrimSpace(tag) == "" {
+			return errors.Wrap(ErrInvalidArguments, "Tags must not include empty tag")
+		}
+	}
+	if x.Country != nil && !isCountryCode(*x.Country) {
+		return errors.Wrapf(ErrInvalidArguments, "Country must be 2 letters country code, got %q", *x.Country)
+	}
+
+	return nil
+}
+
+func isCountryCode(s string) bool {
+	if len(s) != 2 {
+		return false
+	}
+	for _, c := range strings.ToLower(s) {
+		if c < 'a' || 'z' < c {
+			return false
+		}
+	}
+	return true
+}
+
+// SubmitOptions is scan options echoed back by urlscan.io when the scan was submitted.
+type SubmitOptions struct {
+	UserAgent string   `json:"useragent"`
+	Referer   string   `json:"referer"`
+	Tags      []string `json:"tags"`
 }
 
 type submitResponse struct {
-	Visibility string `json:"visibility"`
-	URL        string `json:"url"`
-	Message    string `json:"message"`
-	UUID       string `json:"uuid"`
-	Result     string `json:"result"`
-	API        string `json:"api"`
-	Options    map[string]interface{}
+	Visibility string        `json:"visibility"`
+	URL        string        `json:"url"`
+	Message    string        `json:"message"`
+	UUID       string        `json:"uuid"`
+	Result     string        `json:"result"`
+	API        string        `json:"api"`
+	Country    string        `json:"country"`
+	Options    SubmitOptions `json:"options"`
 }
 
 // Submit sends a request of sandbox execution for specified URL.
 func (x *Client) Submit(args SubmitArguments) (Task, error) {
+	return x.SubmitContext(context.Background(), args)
+}
+
+// SubmitContext is same with Submit, but the request is bound to ctx.
+func (x *Client) SubmitContext(ctx context.Context, args SubmitArguments) (Task, error) {
 	task := Task{
 		client: x,
 	}
 
-	var result submitResponse
-	code, err := x.post("scan", args, &result)
-	if err != nil {
+	if err := args.Validate(); err != nil {
 		return task, err
 	}
-	if code != 200 {
-		return task, errors.Errorf("Unexpected status code: %d", code)
+
+	var result submitResponse
+	if _, err := x.post(ctx, "scan", args.rateTarget(), args, &result); err != nil {
+		return task, err
 	}
 
 	task.url = result.API
 	task.uuid = result.UUID
+	task.resultURL = result.Result
+	task.visibility = Visibility(result.Visibility)
+	task.message = result.Message
+	task.submittedAt = time.Now()
+	task.options = result.Options
 	return task, nil
 }
 
 // ResultTask initiates a task from a previous scan result (uuid) for easy lookup and use.
 func (x *Client) ResultTask(uuid string) Task {
-	task := Task{
-		client: x,
-		uuid:   uuid,
-	}
+	task := newResultTask(x.BaseURL, uuid)
+	task.client = x
 	return task
 }
 
+// newResultTask returns Task of uuid that is not attached to any Client.
+func newResultTask(baseURL, uuid string) Task {
+	return Task{
+		uuid:      uuid,
+		url:       fmt.Sprintf("%s/result/%s/", baseURL, uuid),
+		resultURL: fmt.Sprintf("%s/result/%s/", strings.TrimSuffix(baseURL, "/api/v1"), uuid),
+	}
+}
+
 // Task is returned by Submit() and you can fetch a result of the submitted scan from the Task.
 type Task struct {
-	client *Client
-	uuid   string
-	url    string
-	Result ScanResult
+	client      *Client
+	uuid        string
+	url         string
+	resultURL   string
+	screenshot  string
+	visibility  Visibility
+	message     string
+	submittedAt time.Time
+	options     SubmitOptions
+	Result      ScanResult
+}
+
+// UUID returns ID of the scan.
+func (x *Task) UUID() string {
+	return x.uuid
+}
+
+// ResultURL returns URL of the report page for humans.
+func (x *Task) ResultURL() string {
+	return x.resultURL
+}
+
+// APIURL returns URL of the result API of the scan.
+func (x *Task) APIURL() string {
+	return x.url
+}
+
+// Visibility returns visibility of the scan decided by urlscan.io. It is empty if the Task is not created by Submit.
+func (x *Task) Visibility() Visibility {
+	return x.visibility
+}
+
+// Message returns message of submission response, e.g. "Submission successful". It is empty if the Task is not created by Submit.
+func (x *Task) Message() string {
+	return x.message
 }
 
-func getExpWaitTime(count int) time.Duration {
-	d := (count * count * 100) + 1000
-	if d > 20*1000 {
-		d = 20 * 1000
+// SubmittedAt returns time when Submit received the response. It is zero if the Task is not created by Submit.
+func (x *Task) SubmittedAt() time.Time {
+	return x.submittedAt
+}
+
+// Options returns scan options echoed back by urlscan.io. It is empty if the Task is not created by Submit.
+func (x *Task) Options() SubmitOptions {
+	return x.options
+}
+
+// ErrTaskNotAttached is returned when a Task without Client (e.g. unmarshaled from JSON) sends a request.
+// Use Client.AttachTask to bind the Task to a Client.
+var ErrTaskNotAttached = errors.New("Task is not attached to Client")
+
+// taskJSON is JSON representation of Task.
+type taskJSON struct {
+	UUID        string        `json:"uuid"`
+	APIURL      string        `json:"api_url,omitempty"`
+	ResultURL   string        `json:"result_url,omitempty"`
+	Screenshot  string        `json:"screenshot_url,omitempty"`
+	Visibility  Visibility    `json:"visibility,omitempty"`
+	Message     string        `json:"message,omitempty"`
+	SubmittedAt *time.Time    `json:"submitted_at,omitempty"`
+	Options     SubmitOptions `json:"options"`
+	Result      *ScanResult   `json:"result,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler. Client of the Task is not included, and Result is included only if it is retrieved.
+func (x Task) MarshalJSON() ([]byte, error) {
+	v := taskJSON{
+		UUID:       x.uuid,
+		APIURL:     x.url,
+		ResultURL:  x.resultURL,
+		Screenshot: x.screenshot,
+		Visibility: x.visibility,
+		Message:    x.message,
+		Options:    x.options,
+	}
+	if !x.submittedAt.IsZero() {
+		v.SubmittedAt = &x.submittedAt
+	}
+	if x.Result.Task.UUID != "" {
+		v.Result = &x.Result
 	}
 
-	return time.Millisecond * time.Duration(d)
+	return json.Marshal(v)
 }
 
-// Wait tries to retrieve a result, with a default of 30 retries
-func (x *Task) Wait() error {
-	return x.WaitWithRetry(30)
+// UnmarshalJSON implements json.Unmarshaler. The Task must be bound to a Client by Client.AttachTask before sending a request.
+func (x *Task) UnmarshalJSON(data []byte) error {
+	var v taskJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.UUID == "" {
+		return errors.New("uuid is required for Task")
+	}
+
+	x.uuid = v.UUID
+	x.url = v.APIURL
+	x.resultURL = v.ResultURL
+	x.screenshot = v.Screenshot
+	x.visibility = v.Visibility
+	x.message = v.Message
+	x.options = v.Options
+	x.submittedAt = time.Time{}
+	if v.SubmittedAt != nil {
+		x.submittedAt = *v.SubmittedAt
+	}
+	x.Result = ScanResult{}
+	if v.Result != nil {
+		x.Result = *v.Result
+	}
+
+	return nil
 }
 
-// WaitWithRetry tries to retrieve a result. If scan is not still completed, it retries up to maxRetry times
-func (x *Task) WaitWithRetry(maxRetry int) error {
-	for i := 0; i < maxRetry; i++ {
+// AttachTask binds task to the Client, e.g. after unmarshaling it from JSON, to call Get and Wait.
+func (x *Client) AttachTask(task *Task) {
+	task.client = x
+}
 
-		// Only sleep on retries, not the first time
-		if i > 0 {
-			time.Sleep(getExpWaitTime(i))
+// getResult requests result API once and stores the result into x.Result if the scan is done.
+func (x *Task) getResult(ctx context.Context) (int, error) {
+	if x.client == nil {
+		return 0, ErrTaskNotAttached
+	}
+	code, err := x.client.get(ctx, fmt.Sprintf("result/%s", x.uuid), nil, &x.Result)
+	if err == nil && x.client.unknownFieldsHook != nil {
+		if fields := x.Result.UnknownFields(); len(fields) > 0 {
+			x.client.unknownFieldsHook(x.uuid, fields)
 		}
+	}
+	return code, err
+}
 
-		code, err := x.client.get(fmt.Sprintf("result/%s", x.uuid), nil, &x.Result)
-		if err != nil {
-			return errors.Wrap(err, "Fail to get result query")
-		}
+// sleepContext waits for d, but returns ctx.Err() if ctx is done before that.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
 
-		switch code {
-		case 200:
-			return nil
-		case 400:
-			return errors.New("status: 400")
-		}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
+}
+
+// Wait tries to retrieve a result, with a default of 30 retries. It can be changed by WithWaitOptions.
+func (x *Task) Wait() error {
+	return x.WaitContext(context.Background())
+}
 
-	return errors.Errorf("Timeout of task id: %s", x.uuid)
+// WaitContext is same with Wait, but it gives up when ctx is done.
+func (x *Task) WaitContext(ctx context.Context) error {
+	_, err := x.Await(ctx)
+	return err
+}
+
+// WaitWithRetry tries to retrieve a result. If scan is not still completed, it retries up to maxRetry times
+func (x *Task) WaitWithRetry(maxRetry int) error {
+	return x.WaitWithRetryContext(context.Background(), maxRetry)
+}
+
+// WaitWithRetryContext is same with WaitWithRetry, but it gives up when ctx is done,
+// including while sleeping between retries. Use Await to know status of the scan.
+func (x *Task) WaitWithRetryContext(ctx context.Context, maxRetry int) error {
+	_, err := x.Await(ctx, WaitMaxRetry(maxRetry))
+	return err
 }
 
 // Get tries exactly once to retrieve a result, with no retries
 func (x *Task) Get() error {
-	code, err := x.client.get(fmt.Sprintf("result/%s", x.uuid), nil, &x.Result)
-	if err != nil {
+	return x.GetContext(context.Background())
+}
+
+// GetContext is same with Get, but the request is bound to ctx.
+func (x *Task) GetContext(ctx context.Context) error {
+	if _, err := x.getResult(ctx); err != nil {
 		return errors.Wrap(err, "Fail to get result query")
 	}
-	if code != 200 {
-		return fmt.Errorf("status: %d", code)
-	}
 
 	return nil
 }
@@ -130,34 +373,52 @@ type ScanResult struct {
 	Page  ScanPage  `json:"page"`
 	Stats ScanStats `json:"stats"`
 	Task  ScanTask  `json:"task"`
+	// Verdicts is empty if urlscan.io did not return verdicts.
+	Verdicts ScanVerdicts `json:"verdicts"`
+
+	// Raw is original JSON of the result including fields not modeled by ScanResult. It is not included in MarshalJSON.
+	Raw json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler and keeps data in Raw.
+func (x *ScanResult) UnmarshalJSON(data []byte) error {
+	type scanResult ScanResult
+	var v scanResult
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*x = ScanResult(v)
+	x.Raw = append(json.RawMessage(nil), data...)
+	return nil
 }
 
 // ScanGeo presents GeoLocation information
 type ScanGeo struct {
-	City        string      `json:"city"`
-	Country     string      `json:"country"`
-	CountryName string      `json:"country_name"`
-	LL          []float64   `json:"ll"`
-	Metro       int64       `json:"metro"`
-	Range       interface{} `json:"range"`
-	Region      string      `json:"region"`
-	Zip         int64       `json:"zip"`
+	City        string    `json:"city"`
+	Country     string    `json:"country"`
+	CountryName string    `json:"country_name"`
+	LL          []float64 `json:"ll"`
+	Metro       int64     `json:"metro"`
+	Range       []int64   `json:"range"`
+	Region      string    `json:"region"`
+	Zip         int64     `json:"zip"`
 }
 
 // ScanData presents main result of the scan
 type ScanData struct {
-	Console []interface{} `json:"console"`
+	Console []ScanConsole `json:"console"`
 
 	Cookies []struct {
-		Domain   string  `json:"domain"`
-		Expires  float64 `json:"expires"`
-		HTTPOnly bool    `json:"httpOnly"`
-		Name     string  `json:"name"`
-		Path     string  `json:"path"`
-		Secure   bool    `json:"secure"`
-		Session  bool    `json:"session"`
-		Size     int64   `json:"size"`
-		Value    string  `json:"value"`
+		Domain   string   `json:"domain"`
+		Expires  UnixTime `json:"expires"`
+		HTTPOnly bool     `json:"httpOnly"`
+		Name     string   `json:"name"`
+		Path     string   `json:"path"`
+		Secure   bool     `json:"secure"`
+		Session  bool     `json:"session"`
+		Size     int64    `json:"size"`
+		Value    string   `json:"value"`
 	} `json:"cookies"`
 
 	Globals []struct {
@@ -211,10 +472,11 @@ type ScanData struct {
 				ReferrerPolicy   string `json:"referrerPolicy"`
 				URL              string `json:"url"`
 			} `json:"request"`
-			RequestID string  `json:"requestId"`
-			Timestamp float64 `json:"timestamp"`
-			Type      string  `json:"type"`
-			WallTime  float64 `json:"wallTime"`
+			RequestID string `json:"requestId"`
+			// Timestamp is monotonic seconds. Use WallTime for time of the request.
+			Timestamp float64  `json:"timestamp"`
+			Type      string   `json:"type"`
+			WallTime  UnixTime `json:"wallTime"`
 		} `json:"request"`
 
 		Response struct {
@@ -224,20 +486,20 @@ type ScanData struct {
 				URL    string `json:"url"`
 			} `json:"abp"`
 			Asn struct {
-				Asn         string `json:"asn"`
-				Country     string `json:"country"`
-				Date        string `json:"date"`
-				Description string `json:"description"`
-				IP          string `json:"ip"`
-				Name        string `json:"name"`
-				Registrar   string `json:"registrar"`
-				Route       string `json:"route"`
+				Asn         string   `json:"asn"`
+				Country     string   `json:"country"`
+				Date        DateTime `json:"date"`
+				Description string   `json:"description"`
+				IP          string   `json:"ip"`
+				Name        string   `json:"name"`
+				Registrar   string   `json:"registrar"`
+				Route       string   `json:"route"`
 			} `json:"asn"`
-			DataLength        int64         `json:"dataLength"`
-			EncodedDataLength int64         `json:"encodedDataLength"`
-			Geoip             ScanGeo       `json:"geoip"`
-			Hash              string        `json:"hash"`
-			Hashmatches       []interface{} `json:"hashmatches"`
+			DataLength        int64           `json:"dataLength"`
+			EncodedDataLength int64           `json:"encodedDataLength"`
+			Geoip             ScanGeo         `json:"geoip"`
+			Hash              string          `json:"hash"`
+			Hashmatches       []ScanHashmatch `json:"hashmatches"`
 			Rdns              struct {
 				IP  string `json:"ip"`
 				Ptr string `json:"ptr"`
@@ -253,76 +515,52 @@ type ScanData struct {
 				RemotePort        int64             `json:"remotePort"`
 				RequestHeaders    map[string]string `json:"requestHeaders"`
 				SecurityDetails   struct {
-					CertificateID                     int64         `json:"certificateId"`
-					CertificateTransparencyCompliance string        `json:"certificateTransparencyCompliance"`
-					Cipher                            string        `json:"cipher"`
-					Issuer                            string        `json:"issuer"`
-					KeyExchange                       string        `json:"keyExchange"`
-					KeyExchangeGroup                  string        `json:"keyExchangeGroup"`
-					Protocol                          string        `json:"protocol"`
-					SanList                           []string      `json:"sanList"`
-					SignedCertificateTimestampList    []interface{} `json:"signedCertificateTimestampList"`
-					SubjectName                       string        `json:"subjectName"`
-					ValidFrom                         int64         `json:"validFrom"`
-					ValidTo                           int64         `json:"validTo"`
+					CertificateID                     int64     `json:"certificateId"`
+					CertificateTransparencyCompliance string    `json:"certificateTransparencyCompliance"`
+					Cipher                            string    `json:"cipher"`
+					Issuer                            string    `json:"issuer"`
+					KeyExchange                       string    `json:"keyExchange"`
+					KeyExchangeGroup                  string    `json:"keyExchangeGroup"`
+					Protocol                          string    `json:"protocol"`
+					SanList                           []string  `json:"sanList"`
+					SignedCertificateTimestampList    []ScanSCT `json:"signedCertificateTimestampList"`
+					SubjectName                       string    `json:"subjectName"`
+					ValidFrom                         UnixTime  `json:"validFrom"`
+					ValidTo                           UnixTime  `json:"validTo"`
 				} `json:"securityDetails"`
 				SecurityHeaders []struct {
 					Name  string `json:"name"`
 					Value string `json:"value"`
 				} `json:"securityHeaders"`
-				SecurityState string `json:"securityState"`
-				Status        int64  `json:"status"`
-				StatusText    string `json:"statusText"`
-				Timing        struct {
-					ConnectEnd        float64 `json:"connectEnd"`
-					ConnectStart      float64 `json:"connectStart"`
-					DNSEnd            float64 `json:"dnsEnd"`
-					DNSStart          float64 `json:"dnsStart"`
-					ProxyEnd          int64   `json:"proxyEnd"`
-					ProxyStart        int64   `json:"proxyStart"`
-					PushEnd           int64   `json:"pushEnd"`
-					PushStart         int64   `json:"pushStart"`
-					ReceiveHeadersEnd float64 `json:"receiveHeadersEnd"`
-					RequestTime       float64 `json:"requestTime"`
-					SendEnd           float64 `json:"sendEnd"`
-					SendStart         float64 `json:"sendStart"`
-					SslEnd            float64 `json:"sslEnd"`
-					SslStart          float64 `json:"sslStart"`
-					WorkerReady       int64   `json:"workerReady"`
-					WorkerStart       int64   `json:"workerStart"`
-				} `json:"timing"`
-				URL string `json:"url"`
+				SecurityState string             `json:"securityState"`
+				Status        int64              `json:"status"`
+				StatusText    string             `json:"statusText"`
+				Timing        ScanResourceTiming `json:"timing"`
+				URL           string             `json:"url"`
 			} `json:"response"`
 			Size int64  `json:"size"`
 			Type string `json:"type"`
 		} `json:"response"`
 	} `json:"requests"`
-	Timing struct {
-		BeginNavigation      string `json:"beginNavigation"`
-		DomContentEventFired string `json:"domContentEventFired"`
-		FrameNavigated       string `json:"frameNavigated"`
-		FrameStartedLoading  string `json:"frameStartedLoading"`
-		FrameStoppedLoading  string `json:"frameStoppedLoading"`
-		LoadEventFired       string `json:"loadEventFired"`
-	} `json:"timing"`
+	Timing ScanTiming `json:"timing"`
 }
 
 // ScanLists shows lists
 type ScanLists struct {
 	Asns         []string `json:"asns"`
 	Certificates []struct {
-		Issuer      string `json:"issuer"`
-		SubjectName string `json:"subjectName"`
-		ValidFrom   int64  `json:"validFrom"`
-		ValidTo     int64  `json:"validTo"`
+		Issuer      string   `json:"issuer"`
+		SubjectName string   `json:"subjectName"`
+		ValidFrom   UnixTime `json:"validFrom"`
+		ValidTo     UnixTime `json:"validTo"`
 	} `json:"certificates"`
-	Countries   []string      `json:"countries"`
-	Domains     []string      `json:"domains"`
-	Hashes      []interface{} `json:"hashes"`
-	Ips         []string      `json:"ips"`
-	LinkDomains []string      `json:"linkDomains"`
-	Servers     []string      `json:"servers"`
-	Urls        []string      `json:"urls"`
+	Countries   []string `json:"countries"`
+	Domains     []string `json:"domains"`
+	Hashes      []string `json:"hashes"`
+	Ips         []string `json:"ips"`
+	LinkDomains []string `json:"linkDomains"`
+	Servers     []string `json:"servers"`
+	Urls        []string `json:"urls"`
 }
 
 // ScanMeta presents scan meta data
@@ -338,20 +576,20 @@ type ScanMeta struct {
 		} `json:"abp"`
 		Asn struct {
 			Data []struct {
-				Asn         string `json:"asn"`
-				Country     string `json:"country"`
-				Date        string `json:"date"`
-				Description string `json:"description"`
-				IP          string `json:"ip"`
-				Name        string `json:"name"`
-				Registrar   string `json:"registrar"`
-				Route       string `json:"route"`
+				Asn         string   `json:"asn"`
+				Country     string   `json:"country"`
+				Date        DateTime `json:"date"`
+				Description string   `json:"description"`
+				IP          string   `json:"ip"`
+				Name        string   `json:"name"`
+				Registrar   string   `json:"registrar"`
+				Route       string   `json:"route"`
 			} `json:"data"`
 			State string `json:"state"`
 		} `json:"asn"`
 		Cdnjs struct {
-			Data  []interface{} `json:"data"`
-			State string        `json:"state"`
+			Data  []ScanCdnjs `json:"data"`
+			State string      `json:"state"`
 		} `json:"cdnjs"`
 		Done struct {
 			Data struct {
@@ -360,8 +598,8 @@ type ScanMeta struct {
 			State string `json:"state"`
 		} `json:"done"`
 		Download struct {
-			Data  []interface{} `json:"data"`
-			State string        `json:"state"`
+			Data  []ScanDownload `json:"data"`
+			State string         `json:"state"`
 		} `json:"download"`
 		Geoip struct {
 			Data []struct {
@@ -371,8 +609,8 @@ type ScanMeta struct {
 			State string `json:"state"`
 		} `json:"geoip"`
 		Gsb struct {
-			Data  struct{} `json:"data"`
-			State string   `json:"state"`
+			Data  ScanGsb `json:"data"`
+			State string  `json:"state"`
 		} `json:"gsb"`
 		Rdns struct {
 			Data []struct {
@@ -389,8 +627,8 @@ type ScanMeta struct {
 					Priority int64  `json:"priority"`
 				} `json:"categories"`
 				Confidence []struct {
-					Confidence interface{} `json:"confidence"`
-					Pattern    string      `json:"pattern"`
+					Confidence float64 `json:"confidence"`
+					Pattern    string  `json:"pattern"`
 				} `json:"confidence"`
 				ConfidenceTotal int64  `json:"confidenceTotal"`
 				Icon            string `json:"icon"`
@@ -421,36 +659,37 @@ type ScanTask struct {
 	Options struct {
 		Useragent string `json:"useragent"`
 	} `json:"options"`
-	ReportURL     string `json:"reportURL"`
-	ScreenshotURL string `json:"screenshotURL"`
-	Source        string `json:"source"`
-	Time          string `json:"time"`
-	URL           string `json:"url"`
-	UserAgent     string `json:"userAgent"`
-	UUID          string `json:"uuid"`
-	Visibility    string `json:"visibility"`
+	ReportURL string `json:"reportURL"`
+	// ScreenshotURL can be downloaded by Task.Screenshot or Client.Screenshot.
+	ScreenshotURL string   `json:"screenshotURL"`
+	Source        string   `json:"source"`
+	Time          DateTime `json:"time"`
+	URL           string   `json:"url"`
+	UserAgent     string   `json:"userAgent"`
+	UUID          string   `json:"uuid"`
+	Visibility    string   `json:"visibility"`
 }
 
 // ScanStatsDetail is a detail of scan
 type ScanStatsDetail struct {
-	Compression   string      `json:"compression"`
-	Count         int64       `json:"count"`
-	Countries     []string    `json:"countries"`
-	Domain        string      `json:"domain"`
-	EncodedSize   int64       `json:"encodedSize"`
-	Index         int64       `json:"index"`
-	Initiators    []string    `json:"initiators"`
-	Ips           []string    `json:"ips"`
-	Latency       int64       `json:"latency"`
-	Percentage    interface{} `json:"percentage"`
-	Protocol      string      `json:"protocol"`
-	Protocols     interface{} `json:"protocols"`
-	Redirects     int64       `json:"redirects"`
-	RegDomain     string      `json:"regDomain"`
-	SecurityState interface{} `json:"securityState"`
-	Server        string      `json:"server"`
-	Size          int64       `json:"size"`
-	Type          string      `json:"type"`
+	Compression   string            `json:"compression"`
+	Count         int64             `json:"count"`
+	Countries     []string          `json:"countries"`
+	Domain        string            `json:"domain"`
+	EncodedSize   int64             `json:"encodedSize"`
+	Index         int64             `json:"index"`
+	Initiators    []string          `json:"initiators"`
+	Ips           []string          `json:"ips"`
+	Latency       int64             `json:"latency"`
+	Percentage    float64           `json:"percentage"`
+	Protocol      string            `json:"protocol"`
+	Protocols     map[string]int64  `json:"protocols"`
+	Redirects     int64             `json:"redirects"`
+	RegDomain     string            `json:"regDomain"`
+	SecurityState ScanSecurityState `json:"securityState"`
+	Server        string            `json:"server"`
+	Size          int64             `json:"size"`
+	Type          string            `json:"type"`
 	SubDomains    []struct {
 		Domain string `json:"domain"`
 		Failed bool   `json:"failed"`
@@ -464,24 +703,25 @@ type ScanStats struct {
 	DomainStats    []ScanStatsDetail `json:"domainStats"`
 	IPStats        []struct {
 		Asn struct {
-			Asn         string `json:"asn"`
-			Country     string `json:"country"`
-			Date        string `json:"date"`
-			Description string `json:"description"`
-			IP          string `json:"ip"`
-			Name        string `json:"name"`
-			Registrar   string `json:"registrar"`
-			Route       string `json:"route"`
+			Asn         string   `json:"asn"`
+			Country     string   `json:"country"`
+			Date        DateTime `json:"date"`
+			Description string   `json:"description"`
+			IP          string   `json:"ip"`
+			Name        string   `json:"name"`
+			Registrar   string   `json:"registrar"`
+			Route       string   `json:"route"`
 		} `json:"asn"`
-		Count       interface{} `json:"count"`
-		Countries   []string    `json:"countries"`
-		DNS         struct{}    `json:"dns"`
-		Domains     []string    `json:"domains"`
-		EncodedSize int64       `json:"encodedSize"`
-		Geoip       ScanGeo     `json:"geoip"`
-		Index       int64       `json:"index"`
-		IP          string      `json:"ip"`
-		Ipv6        bool        `json:"ipv6"`
+		Count     int64    `json:"count"`
+		Countries []string `json:"countries"`
+		// DNS is not documented and kept as raw JSON. Its keys are reported by UnknownFields.
+		DNS         json.RawMessage `json:"dns"`
+		Domains     []string        `json:"domains"`
+		EncodedSize int64           `json:"encodedSize"`
+		Geoip       ScanGeo         `json:"geoip"`
+		Index       int64           `json:"index"`
+		IP          string          `json:"ip"`
+		Ipv6        bool            `json:"ipv6"`
 		Rdns        struct {
 			IP  string `json:"ip"`
 			Ptr string `json:"ptr"`
@@ -501,3 +741,102 @@ type ScanStats struct {
 	TotalLinks       int64             `json:"totalLinks"`
 	UniqCountries    int64             `json:"uniqCountries"`
 }
+
+// ScanConsole is a message in browser console.
+type ScanConsole struct {
+	Message struct {
+		Source    string        `json:"source"`
+		Level     string        `json:"level"`
+		Text      string        `json:"text"`
+		URL       string        `json:"url"`
+		Line      int64         `json:"line"`
+		Column    int64         `json:"column"`
+		Timestamp UnixMilliTime `json:"timestamp"`
+	} `json:"message"`
+}
+
+// ScanHashmatch is a known file matched with hash of a response, e.g. a library in public repository.
+type ScanHashmatch struct {
+	Source     string `json:"source"`
+	File       string `json:"file"`
+	Project    string `json:"project"`
+	ProjectURL string `json:"project_url"`
+	URL        string `json:"url"`
+}
+
+// ScanSCT is a signed certificate timestamp of a certificate.
+type ScanSCT struct {
+	Status             string        `json:"status"`
+	Origin             string        `json:"origin"`
+	LogDescription     string        `json:"logDescription"`
+	LogID              string        `json:"logId"`
+	Timestamp          UnixMilliTime `json:"timestamp"`
+	HashAlgorithm      string        `json:"hashAlgorithm"`
+	SignatureAlgorithm string        `json:"signatureAlgorithm"`
+	SignatureData      string        `json:"signatureData"`
+}
+
+// ScanCdnjs is a script matched with cdnjs library.
+type ScanCdnjs struct {
+	Hash    string   `json:"hash"`
+	Matches []string `json:"matches"`
+}
+
+// ScanDownload is a file downloaded by the page.
+type ScanDownload struct {
+	Filename        string `json:"filename"`
+	FileSize        int64  `json:"filesize"`
+	MimeType        string `json:"mimeType"`
+	MimeDescription string `json:"mimeDescription"`
+	SHA256          string `json:"sha256"`
+	URL             string `json:"url"`
+	State           string `json:"state"`
+	ReceivedBytes   int64  `json:"receivedBytes"`
+	TotalBytes      int64  `json:"totalBytes"`
+}
+
+// ScanGsb is result of Google Safe Browsing lookup.
+type ScanGsb struct {
+	Matches []ScanGsbMatch `json:"matches"`
+}
+
+// ScanGsbMatch is a threat matched by Google Safe Browsing.
+type ScanGsbMatch struct {
+	ThreatType      string `json:"threatType"`
+	PlatformType    string `json:"platformType"`
+	ThreatEntryType string `json:"threatEntryType"`
+	CacheDuration   string `json:"cacheDuration"`
+	Threat          struct {
+		URL string `json:"url"`
+	} `json:"threat"`
+}
+
+// ScanSecurityState is security state of requests. urlscan.io returns a state (e.g. "secure") for some stats
+// and number of requests by state for others.
+type ScanSecurityState struct {
+	State  string
+	Counts map[string]int64
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (x *ScanSecurityState) UnmarshalJSON(data []byte) error {
+	*x = ScanSecurityState{}
+	if string(data) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(data, &x.State); err == nil {
+		return nil
+	}
+	return json.Unmarshal(data, &x.Counts)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (x ScanSecurityState) MarshalJSON() ([]byte, error) {
+	if x.Counts != nil {
+		return json.Marshal(x.Counts)
+	}
+	if x.State != "" {
+		return json.Marshal(x.State)
+	}
+	return []byte("null"), nil
+}
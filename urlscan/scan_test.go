@@ -1,12 +1,16 @@
 package urlscan_test
 
 import (
+	"context"
 	"encoding/json"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"testing"
 
 	"github.com/m-mizutani/urlscan-go/urlscan"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -28,3 +32,203 @@ func TestResult(t *testing.T) {
 	require.NoError(t, err)
 
 }
+
+func TestSubmitArgumentsValidate(t *testing.T) {
+	testCases := []struct {
+		title string
+		args  urlscan.SubmitArguments
+		valid bool
+	}{
+		{"minimum", urlscan.SubmitArguments{URL: "https://example.com"}, true},
+		{"no URL", urlscan.SubmitArguments{}, false},
+		{"visibility", urlscan.SubmitArguments{URL: "https://example.com", Visibility: urlscan.VisibilityUnlisted}, true},
+		{"unknown visibility", urlscan.SubmitArguments{URL: "https://example.com", Visibility: "secret"}, false},
+		{"public", urlscan.SubmitArguments{URL: "https://example.com", Public: urlscan.String("on")}, true},
+		{"invalid public", urlscan.SubmitArguments{URL: "https://example.com", Public: urlscan.String("yes")}, false},
+		{"public and visibility", urlscan.SubmitArguments{URL: "https://example.com", Public: urlscan.String("on"), Visibility: urlscan.VisibilityPublic}, false},
+		{"country", urlscan.SubmitArguments{URL: "https://example.com", Country: urlscan.String("de")}, true},
+		{"invalid country", urlscan.SubmitArguments{URL: "https://example.com", Country: urlscan.String("germany")}, false},
+		{"tags", urlscan.SubmitArguments{URL: "https://example.com", Tags: []string{"phishing", "incident-123"}}, true},
+		{"empty tag", urlscan.SubmitArguments{URL: "https://example.com", Tags: []string{"phishing", " "}}, false},
+		{"too many tags", urlscan.SubmitArguments{URL: "https://example.com", Tags: make([]string, urlscan.MaxSubmitTags+1)}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			err := tc.args.Validate()
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, urlscan.ErrInvalidArguments), "err: %v", err)
+			}
+		})
+	}
+}
+
+func TestSubmitInvalidArgumentsNotSent(t *testing.T) {
+	var called bool
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	_, err := client.Submit(urlscan.SubmitArguments{URL: "https://example.com", Visibility: "secret"})
+	require.Error(t, err)
+	assert.False(t, called)
+}
+
+func TestSubmitWithOptions(t *testing.T) {
+	var body map[string]interface{}
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Write([]byte(`{
+			"message": "Submission successful",
+			"uuid": "0c5ba6ab-1234-4e72-9f4a-4d0d2bd6c0a1",
+			"result": "https://urlscan.io/result/0c5ba6ab-1234-4e72-9f4a-4d0d2bd6c0a1/",
+			"api": "https://urlscan.io/api/v1/result/0c5ba6ab-1234-4e72-9f4a-4d0d2bd6c0a1/",
+			"visibility": "unlisted",
+			"options": {"useragent": "my-agent", "tags": ["phishing"]},
+			"url": "https://example.com",
+			"country": "de"
+		}`))
+	}))
+
+	task, err := client.Submit(urlscan.SubmitArguments{
+		URL:            "https://example.com",
+		CustomAgent:    urlscan.String("my-agent"),
+		Visibility:     urlscan.VisibilityUnlisted,
+		Tags:           []string{"phishing"},
+		Country:        urlscan.String("de"),
+		OverrideSafety: urlscan.Bool(true),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "unlisted", body["visibility"])
+	assert.Equal(t, []interface{}{"phishing"}, body["tags"])
+	assert.Equal(t, "de", body["country"])
+	assert.Equal(t, true, body["overrideSafety"])
+	assert.Nil(t, body["public"])
+
+	assert.Equal(t, "0c5ba6ab-1234-4e72-9f4a-4d0d2bd6c0a1", task.UUID())
+	assert.Equal(t, "https://urlscan.io/result/0c5ba6ab-1234-4e72-9f4a-4d0d2bd6c0a1/", task.ResultURL())
+	assert.Equal(t, "https://urlscan.io/api/v1/result/0c5ba6ab-1234-4e72-9f4a-4d0d2bd6c0a1/", task.APIURL())
+	assert.Equal(t, urlscan.VisibilityUnlisted, task.Visibility())
+	assert.Equal(t, "Submission successful", task.Message())
+	assert.False(t, task.SubmittedAt().IsZero())
+	assert.Equal(t, "my-agent", task.Options().UserAgent)
+	assert.Equal(t, []string{"phishing"}, task.Options().Tags)
+}
+
+func TestResultTaskURLs(t *testing.T) {
+	client := urlscan.NewClient("test-api-key")
+	task := client.ResultTask("0c5ba6ab-1234-4e72-9f4a-4d0d2bd6c0a1")
+
+	assert.Equal(t, "0c5ba6ab-1234-4e72-9f4a-4d0d2bd6c0a1", task.UUID())
+	assert.Equal(t, "https://urlscan.io/result/0c5ba6ab-1234-4e72-9f4a-4d0d2bd6c0a1/", task.ResultURL())
+	assert.Equal(t, "https://urlscan.io/api/v1/result/0c5ba6ab-1234-4e72-9f4a-4d0d2bd6c0a1/", task.APIURL())
+	assert.True(t, task.SubmittedAt().IsZero())
+}
+
+func TestTaskJSONRoundTrip(t *testing.T) {
+	client := newErrorClient(t, 200, nil, `{"task":{"uuid":"`+testScanUUID+`","url":"https://example.com/"}}`)
+	task := client.ResultTask(testScanUUID)
+	require.NoError(t, task.Get())
+
+	raw, err := json.Marshal(task)
+	require.NoError(t, err)
+
+	var restored urlscan.Task
+	require.NoError(t, json.Unmarshal(raw, &restored))
+	assert.Equal(t, task.UUID(), restored.UUID())
+	assert.Equal(t, task.APIURL(), restored.APIURL())
+	assert.Equal(t, task.ResultURL(), restored.ResultURL())
+	assert.Equal(t, "https://example.com/", restored.Result.Task.URL)
+
+	// Restored task has no client until it is attached.
+	assert.True(t, errors.Is(restored.Get(), urlscan.ErrTaskNotAttached))
+	_, err = restored.Status(context.Background())
+	assert.True(t, errors.Is(err, urlscan.ErrTaskNotAttached))
+
+	client.AttachTask(&restored)
+	require.NoError(t, restored.Get())
+}
+
+func TestTaskUnmarshalJSONRequiresUUID(t *testing.T) {
+	var task urlscan.Task
+	assert.Error(t, json.Unmarshal([]byte(`{"api_url":"https://urlscan.io/api/v1/result/x/"}`), &task))
+}
+
+func TestScanVerdicts(t *testing.T) {
+	raw := `{"verdicts": {
+		"overall": {"score": 100, "categories": ["phishing"], "brands": ["paypal"], "tags": [], "malicious": true, "hasVerdicts": 1},
+		"urlscan": {"score": 100, "categories": ["phishing"],
+			"brands": [{"key": "paypal", "name": "PayPal", "country": ["International"], "vertical": ["Financial"]}], "malicious": true},
+		"engines": {"score": 0, "malicious": ["engineA"], "benign": [], "maliciousTotal": 1, "benignTotal": 0,
+			"verdicts": [{"engine": "engineA", "classification": "malicious", "categories": ["malware"]}], "enginesTotal": 2},
+		"community": {"score": 0, "votes": [], "votesTotal": 0, "votesMalicious": 0, "votesBenign": 0}
+	}}`
+
+	var result urlscan.ScanResult
+	require.NoError(t, json.Unmarshal([]byte(raw), &result))
+
+	verdicts := result.Verdicts
+	assert.True(t, verdicts.IsMalicious())
+	assert.Equal(t, int64(100), verdicts.Score())
+	assert.Equal(t, []urlscan.Brand{{Key: "paypal", Name: "PayPal", Country: []string{"International"}, Vertical: []string{"Financial"}}}, verdicts.Brands())
+	assert.Equal(t, []string{"phishing"}, verdicts.Categories())
+	assert.Equal(t, "engineA", verdicts.Engines.Malicious[0].Engine)
+	assert.Equal(t, "malicious", verdicts.Engines.Verdicts[0].Classification)
+	assert.Equal(t, int64(2), verdicts.Engines.EnginesTotal)
+}
+
+func TestScanVerdictsEmpty(t *testing.T) {
+	var result urlscan.ScanResult
+	require.NoError(t, json.Unmarshal([]byte(`{"task":{"uuid":"x"}}`), &result))
+	assert.False(t, result.Verdicts.IsMalicious())
+	assert.Equal(t, 0, len(result.Verdicts.Brands()))
+}
+
+func TestScanResultTypedFields(t *testing.T) {
+	raw := `{
+		"data": {
+			"console": [{"message": {"source": "network", "level": "error", "text": "Failed to load resource", "url": "https://example.com/x.js", "line": 1, "column": 2}}],
+			"requests": [{"response": {"hashmatches": [{"source": "Github", "file": "dist/jquery.min.js", "project": "jquery", "project_url": "https://github.com/jquery/jquery", "url": "https://example.com/jquery.min.js"}]}}]
+		},
+		"lists": {"hashes": ["0123abcd"]},
+		"meta": {"processors": {
+			"gsb": {"state": "done", "data": {"matches": [{"threatType": "SOCIAL_ENGINEERING", "platformType": "ANY_PLATFORM", "threatEntryType": "URL", "threat": {"url": "https://example.com/"}}]}},
+			"cdnjs": {"state": "done", "data": [{"hash": "0123abcd", "matches": ["jquery/3.4.1/jquery.min.js"]}]},
+			"download": {"state": "done", "data": [{"filename": "a.exe", "filesize": 1024, "sha256": "deadbeef", "url": "https://example.com/a.exe"}]}
+		}},
+		"stats": {
+			"protocolStats": [{"protocol": "h2", "count": 3, "percentage": 75.5, "securityState": {"secure": 3}}],
+			"tlsStats": [{"count": 3, "protocols": {"TLS 1.3 / AES_128_GCM": 3}, "securityState": "secure"}],
+			"ipStats": [{"ip": "192.0.2.1", "count": null, "dns": {}, "geoip": {"range": [3221225984, 3221226239]}}]
+		}
+	}`
+
+	var result urlscan.ScanResult
+	require.NoError(t, json.Unmarshal([]byte(raw), &result))
+
+	assert.Equal(t, "error", result.Data.Console[0].Message.Level)
+	assert.Equal(t, "https://example.com/x.js", result.Data.Console[0].Message.URL)
+	assert.Equal(t, "jquery", result.Data.Requests[0].Response.Hashmatches[0].Project)
+	assert.Equal(t, []string{"0123abcd"}, result.Lists.Hashes)
+	assert.Equal(t, "SOCIAL_ENGINEERING", result.Meta.Processors.Gsb.Data.Matches[0].ThreatType)
+	assert.Equal(t, "https://example.com/", result.Meta.Processors.Gsb.Data.Matches[0].Threat.URL)
+	assert.Equal(t, []string{"jquery/3.4.1/jquery.min.js"}, result.Meta.Processors.Cdnjs.Data[0].Matches)
+	assert.Equal(t, int64(1024), result.Meta.Processors.Download.Data[0].FileSize)
+	assert.Equal(t, "deadbeef", result.Meta.Processors.Download.Data[0].SHA256)
+	assert.Equal(t, 75.5, result.Stats.ProtocolStats[0].Percentage)
+	assert.Equal(t, int64(3), result.Stats.ProtocolStats[0].SecurityState.Counts["secure"])
+	assert.Equal(t, "secure", result.Stats.TLSStats[0].SecurityState.State)
+	assert.Equal(t, int64(3), result.Stats.TLSStats[0].Protocols["TLS 1.3 / AES_128_GCM"])
+	assert.Equal(t, []int64{3221225984, 3221226239}, result.Stats.IPStats[0].Geoip.Range)
+
+	// Marshaled result can be decoded again, e.g. by Task JSON.
+	encoded, err := json.Marshal(result)
+	require.NoError(t, err)
+	var decoded urlscan.ScanResult
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, result.Stats.TLSStats[0].SecurityState, decoded.Stats.TLSStats[0].SecurityState)
+	assert.Equal(t, result.Stats.ProtocolStats[0].SecurityState, decoded.Stats.ProtocolStats[0].SecurityState)
+}
@@ -0,0 +1,166 @@
+package urlscan
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// lenientJSON is implemented by types that have custom UnmarshalJSON only to accept alternative forms (e.g. a string
+// instead of an object). Fields of such types are still checked by UnknownFields when the value is an object.
+type lenientJSON interface {
+	lenientJSON()
+}
+
+func (x Brand) lenientJSON()         {}
+func (x EngineVerdict) lenientJSON() {}
+func (x ScanResult) lenientJSON()    {}
+
+var (
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	rawMessageType  = reflect.TypeOf(json.RawMessage{})
+	lenientType     = reflect.TypeOf((*lenientJSON)(nil)).Elem()
+)
+
+// UnknownFields returns paths of JSON keys in data that are not decoded into v, e.g. "data.requests[].response.newField".
+// Keys of maps are shown as "*", and keys of objects kept in json.RawMessage fields are reported because they are
+// not modeled. It is useful to detect changes of urlscan.io API against saved responses.
+// v is a pointer to or a value of the type to be decoded, such as &ScanResult{}.
+func UnknownFields(data []byte, v interface{}) ([]string, error) {
+	if !json.Valid(data) {
+		return nil, errors.New("Invalid JSON data")
+	}
+
+	found := make(map[string]bool)
+	walkUnknownFields("", data, reflect.TypeOf(v), found)
+
+	fields := make([]string, 0, len(found))
+	for field := range found {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+// UnknownFields returns JSON keys of the result that are not modeled by ScanResult. It returns nil if the result was not decoded from JSON.
+func (x ScanResult) UnknownFields() []string {
+	if len(x.Raw) == 0 {
+		return nil
+	}
+	fields, _ := UnknownFields(x.Raw, x)
+	return fields
+}
+
+// WithUnknownFieldsHook sets a function called with unknown fields of ScanResult (see ScanResult.UnknownFields)
+// when a result that has unknown fields is retrieved. It helps to notice changes of urlscan.io API.
+func WithUnknownFieldsHook(hook func(uuid string, fields []string)) Option {
+	return func(x *Client) {
+		x.unknownFieldsHook = hook
+	}
+}
+
+func walkUnknownFields(path string, raw json.RawMessage, t reflect.Type, found map[string]bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == rawMessageType {
+		// Kept without modeling, so any key of an object is unknown.
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return
+		}
+		for key := range obj {
+			found[joinFieldPath(path, key)] = true
+		}
+		return
+	}
+	if reflect.PtrTo(t).Implements(unmarshalerType) && !reflect.PtrTo(t).Implements(lenientType) {
+		// Decoded by custom way, e.g. time.Time.
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return
+		}
+
+		fields := make(map[string]reflect.Type)
+		collectJSONFields(t, fields)
+		for key, value := range obj {
+			fieldType, ok := fields[key]
+			if !ok {
+				// encoding/json matches keys case-insensitively.
+				fieldType, ok = fields[strings.ToLower(key)]
+			}
+			if !ok {
+				found[joinFieldPath(path, key)] = true
+				continue
+			}
+			walkUnknownFields(joinFieldPath(path, key), value, fieldType, found)
+		}
+
+	case reflect.Slice, reflect.Array:
+		var list []json.RawMessage
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return
+		}
+		for _, value := range list {
+			walkUnknownFields(path+"[]", value, t.Elem(), found)
+		}
+
+	case reflect.Map:
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return
+		}
+		for _, value := range obj {
+			walkUnknownFields(joinFieldPath(path, "*"), value, t.Elem(), found)
+		}
+	}
+}
+
+// collectJSONFields sets JSON key and type of exported fields of t into fields. Keys are also set in lower case.
+func collectJSONFields(t reflect.Type, fields map[string]reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				collectJSONFields(ft, fields)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+		if _, ok := fields[strings.ToLower(name)]; !ok {
+			fields[strings.ToLower(name)] = f.Type
+		}
+	}
+}
+
+func joinFieldPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,77 @@
+package urlscan_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m-mizutani/urlscan-go/urlscan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDriftResult = `{
+	"task": {"uuid": "` + testScanUUID + `", "time": "2020-05-08T06:10:36.123Z", "newTaskField": 1},
+	"page": {"url": "https://example.com/", "Domain": "example.com"},
+	"data": {"requests": [{"response": {"hashmatches": [{"source": "x", "sha1": "abc"}]}}]},
+	"stats": {"protocolStats": [{"protocols": {"h2": 1}, "securityState": {"secure": 1}}]},
+	"verdicts": {"urlscan": {"brands": ["paypal", {"key": "x", "logo": "x.png"}]}},
+	"submitter": {"country": "JP"}
+}`
+
+func TestScanResultRaw(t *testing.T) {
+	var result urlscan.ScanResult
+	require.NoError(t, json.Unmarshal([]byte(testDriftResult), &result))
+
+	assert.Equal(t, testScanUUID, result.Task.UUID)
+	var raw map[string]interface{}
+	require.NoError(t, json.Unmarshal(result.Raw, &raw))
+	assert.Equal(t, map[string]interface{}{"country": "JP"}, raw["submitter"])
+
+	assert.Equal(t, []string{
+		"data.requests[].response.hashmatches[].sha1",
+		"submitter",
+		"task.newTaskField",
+		"verdicts.urlscan.brands[].logo",
+	}, result.UnknownFields())
+}
+
+func TestUnknownFields(t *testing.T) {
+	fields, err := urlscan.UnknownFields([]byte(`{"results":[{"_id":"x","page":{"newField":1}}],"total":1,"took":3}`), &urlscan.SearchResponse{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"results[].page.newField", "took"}, fields)
+
+	_, err = urlscan.UnknownFields([]byte(`{broken`), &urlscan.ScanResult{})
+	assert.Error(t, err)
+
+	assert.Nil(t, urlscan.ScanResult{}.UnknownFields())
+
+	// Undocumented DNS of ipStats is kept as raw JSON and its keys are reported.
+	data := []byte(`{"stats":{"ipStats":[{"ip":"192.0.2.1","dns":{"a":["192.0.2.1"]}},{"dns":{}}]}}`)
+	fields, err = urlscan.UnknownFields(data, &urlscan.ScanResult{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"stats.ipStats[].dns.a"}, fields)
+
+	var result urlscan.ScanResult
+	require.NoError(t, json.Unmarshal(data, &result))
+	assert.JSONEq(t, `{"a":["192.0.2.1"]}`, string(result.Stats.IPStats[0].DNS))
+}
+
+func TestUnknownFieldsHook(t *testing.T) {
+	var reported []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testDriftResult))
+	}))
+	defer server.Close()
+
+	client := urlscan.NewClient("test-api-key", urlscan.WithBaseURL(server.URL),
+		urlscan.WithUnknownFieldsHook(func(uuid string, fields []string) {
+			assert.Equal(t, testScanUUID, uuid)
+			reported = fields
+		}))
+
+	task := client.ResultTask(testScanUUID)
+	require.NoError(t, task.Get())
+	assert.Contains(t, reported, "submitter")
+}
@@ -0,0 +1,179 @@
+package urlscan
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrScreenshotNotReady is returned (wrapped) by Task.Screenshot when the screenshot is still not available after retries.
+var ErrScreenshotNotReady = errors.New("Screenshot is not ready")
+
+// screenshotOutput writes screenshot image of a response into w. If w is buf, it is reset by each response and
+// a response broken in the middle can be retried. Otherwise the response is not retried after a part of the image
+// is written into w, not to write the image twice.
+type screenshotOutput struct {
+	w           io.Writer
+	buf         *bytes.Buffer
+	written     int64
+	contentType string
+}
+
+func newScreenshotBuffer() *screenshotOutput {
+	buf := &bytes.Buffer{}
+	return &screenshotOutput{w: buf, buf: buf}
+}
+
+func (x *screenshotOutput) decodeStream(resp *http.Response) error {
+	if x.buf != nil {
+		x.buf.Reset()
+	}
+	x.contentType = resp.Header.Get("Content-Type")
+
+	body := &bodyReader{r: resp.Body}
+	n, err := io.Copy(x.w, body)
+	x.written += n
+	switch {
+	case err == nil:
+		return nil
+	case body.err == nil:
+		return errors.Wrap(err, "Fail to write screenshot")
+	case x.buf == nil && x.written > 0:
+		return errors.Wrap(body.err, "Fail to read screenshot after partially written")
+	default:
+		// Broken connection while reading the image can be retried by RetryPolicy as other transport errors.
+		return newNetworkError(resp.Request, body.err)
+	}
+}
+
+// screenshotURL returns URL of screenshot image derived from BaseURL. It is not under API path, so "/api/v1" of BaseURL
+// is removed, e.g. https://urlscan.io/screenshots/<uuid>.png. BaseURL without "/api/v1" is used as it is.
+func (x Client) screenshotURL(uuid string) string {
+	return fmt.Sprintf("%s/screenshots/%s.png", strings.TrimSuffix(x.BaseURL, "/api/v1"), uuid)
+}
+
+// downloadScreenshot requests screenshot image of uri into output. API key is sent only to the host of BaseURL.
+func (x *Client) downloadScreenshot(ctx context.Context, uri string, output *screenshotOutput) (int, error) {
+	x.log().Debug("Generated Query", "uri", uri)
+
+	req, err := x.newRequest(ctx, "GET", uri, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "Fail to create urlscan.io screenshot request")
+	}
+	if base, err := url.Parse(x.BaseURL); err == nil && base.Host == req.URL.Host {
+		req.Header.Add("API-Key", x.apiKey)
+	}
+
+	return x.do(req, rateTarget{path: "screenshots"}, output)
+}
+
+// ScreenshotTo streams screenshot of the scan uuid into w and returns its content type, e.g. "image/png".
+// The request uses API key, transport and retry of the Client. If the screenshot is not available yet,
+// it returns APIError of 404 (see IsNotFound) and nothing is written into w. If the download fails in the middle,
+// w may have a part of the image and the request is not retried. Use Screenshot to retry such failure.
+func (x *Client) ScreenshotTo(ctx context.Context, uuid string, w io.Writer) (string, error) {
+	output := &screenshotOutput{w: w}
+	if _, err := x.downloadScreenshot(ctx, x.screenshotURL(uuid), output); err != nil {
+		return "", err
+	}
+	return output.contentType, nil
+}
+
+// Screenshot downloads screenshot of the scan uuid and returns the image and its content type. See ScreenshotTo.
+func (x *Client) Screenshot(ctx context.Context, uuid string) ([]byte, string, error) {
+	output := newScreenshotBuffer()
+	if _, err := x.downloadScreenshot(ctx, x.screenshotURL(uuid), output); err != nil {
+		return nil, "", err
+	}
+	return output.buf.Bytes(), output.contentType, nil
+}
+
+// screenshotURL returns URL of screenshot given by urlscan.io, i.e. ScanTask.ScreenshotURL of the result or
+// SearchResult.Screenshot. URL derived from BaseURL is used only if neither is available.
+func (x *Task) screenshotURL() string {
+	switch {
+	case x.Result.Task.ScreenshotURL != "":
+		return x.Result.Task.ScreenshotURL
+	case x.screenshot != "":
+		return x.screenshot
+	default:
+		return x.client.screenshotURL(x.uuid)
+	}
+}
+
+// ScreenshotTo streams screenshot of the Task into w and returns its content type. The screenshot may be available
+// a little after the result, so 404 response is retried like Await. WaitMaxRetry, WaitBackoff, WaitInitialDelay and
+// WaitOnPoll of opts are applied, and Status of PollEvent is TaskStatusDone when the screenshot is downloaded.
+// Like Client.ScreenshotTo, w may have a part of the image if the download fails in the middle.
+func (x *Task) ScreenshotTo(ctx context.Context, w io.Writer, opts ...WaitOption) (string, error) {
+	output := &screenshotOutput{w: w}
+	if err := x.downloadScreenshot(ctx, output, opts); err != nil {
+		return "", err
+	}
+	return output.contentType, nil
+}
+
+// Screenshot downloads screenshot of the Task and returns the image and its content type. See Task.ScreenshotTo.
+func (x *Task) Screenshot(ctx context.Context, opts ...WaitOption) ([]byte, string, error) {
+	output := newScreenshotBuffer()
+	if err := x.downloadScreenshot(ctx, output, opts); err != nil {
+		return nil, "", err
+	}
+	return output.buf.Bytes(), output.contentType, nil
+}
+
+// downloadScreenshot requests screenshot of the Task into output and retries 404 response according to opts.
+func (x *Task) downloadScreenshot(ctx context.Context, output *screenshotOutput, opts []WaitOption) error {
+	if x.client == nil {
+		return ErrTaskNotAttached
+	}
+	cfg := newWaitConfig(x.client, opts)
+	uri := x.screenshotURL()
+
+	start := time.Now()
+	final := PollEvent{Status: TaskStatusUnknown, Final: true}
+	err := func() error {
+		delay := cfg.initialDelay
+		var lastErr error
+		for i := 0; i < cfg.maxRetry; i++ {
+			if delay > 0 {
+				if err := sleepContext(ctx, delay); err != nil {
+					return errors.Wrap(err, "Interrupted while waiting screenshot")
+				}
+			}
+
+			code, err := x.client.downloadScreenshot(ctx, uri, output)
+			final.Attempt, final.Status = i+1, statusOf(err)
+			retry := IsNotFound(err)
+
+			delay = 0
+			if retry && i+1 < cfg.maxRetry {
+				delay = cfg.backoff.Delay(i + 1)
+			}
+			cfg.onPoll(PollEvent{
+				Attempt:    final.Attempt,
+				StatusCode: code,
+				Status:     final.Status,
+				NextDelay:  delay,
+				Elapsed:    time.Since(start),
+			})
+
+			if !retry {
+				return err
+			}
+			lastErr = err
+		}
+		return errors.Wrapf(ErrScreenshotNotReady, "task id: %s, last error: %v", x.uuid, lastErr)
+	}()
+
+	final.Elapsed, final.Err = time.Since(start), err
+	cfg.onPoll(final)
+	return err
+}
@@ -0,0 +1,265 @@
+package urlscan_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/urlscan-go/urlscan"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPNG = []byte("\x89PNG\r\n\x1a\nimage")
+
+// newScreenshotClient returns a client of which screenshot is not found for first notReady requests.
+func newScreenshotClient(t *testing.T, notReady int32, count *int32) urlscan.Client {
+	return newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/screenshots/"+testScanUUID+".png", r.URL.Path)
+		assert.Equal(t, "test-api-key", r.Header.Get("API-Key"))
+
+		if atomic.AddInt32(count, 1) <= notReady {
+			w.WriteHeader(404)
+			w.Write([]byte(`{"message":"Not Found","status":404}`))
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(testPNG)
+	}))
+}
+
+func TestClientScreenshot(t *testing.T) {
+	var count int32
+	client := newScreenshotClient(t, 0, &count)
+
+	img, contentType, err := client.Screenshot(context.Background(), testScanUUID)
+	require.NoError(t, err)
+	assert.Equal(t, testPNG, img)
+	assert.Equal(t, "image/png", contentType)
+
+	var buf bytes.Buffer
+	contentType, err = client.ScreenshotTo(context.Background(), testScanUUID, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, testPNG, buf.Bytes())
+	assert.Equal(t, "image/png", contentType)
+}
+
+func TestClientScreenshotNotReady(t *testing.T) {
+	var count int32
+	client := newScreenshotClient(t, 1, &count)
+
+	var buf bytes.Buffer
+	_, err := client.ScreenshotTo(context.Background(), testScanUUID, &buf)
+	assert.True(t, urlscan.IsNotFound(err))
+	assert.Equal(t, 0, buf.Len())
+}
+
+func TestTaskScreenshot(t *testing.T) {
+	var count int32
+	client := newScreenshotClient(t, 2, &count)
+	task := client.ResultTask(testScanUUID)
+
+	img, contentType, err := task.Screenshot(context.Background(), fastWait...)
+	require.NoError(t, err)
+	assert.Equal(t, testPNG, img)
+	assert.Equal(t, "image/png", contentType)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&count))
+}
+
+func TestTaskScreenshotTimeout(t *testing.T) {
+	var count int32
+	client := newScreenshotClient(t, 100, &count)
+	task := client.ResultTask(testScanUUID)
+
+	_, _, err := task.Screenshot(context.Background(), append(fastWait, urlscan.WaitMaxRetry(3))...)
+	assert.True(t, errors.Is(err, urlscan.ErrScreenshotNotReady))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&count))
+
+	var detached urlscan.Task
+	_, _, err = detached.Screenshot(context.Background())
+	assert.True(t, errors.Is(err, urlscan.ErrTaskNotAttached))
+}
+
+func TestTaskScreenshotWaitOptions(t *testing.T) {
+	var count int32
+	client := newScreenshotClient(t, 2, &count)
+	task := client.ResultTask(testScanUUID)
+
+	var events []urlscan.PollEvent
+	start := time.Now()
+	_, _, err := task.Screenshot(context.Background(),
+		urlscan.WaitBackoff(nil),
+		urlscan.WaitInitialDelay(50*time.Millisecond),
+		urlscan.WaitOnPoll(func(ev urlscan.PollEvent) { events = append(events, ev) }),
+	)
+	require.NoError(t, err)
+	assert.True(t, time.Since(start) >= 50*time.Millisecond)
+
+	require.Equal(t, 4, len(events))
+	assert.Equal(t, http.StatusNotFound, events[0].StatusCode)
+	assert.Equal(t, urlscan.TaskStatusNotFound, events[0].Status)
+	assert.True(t, events[0].NextDelay > 0)
+	assert.Equal(t, http.StatusOK, events[2].StatusCode)
+	assert.Equal(t, urlscan.TaskStatusDone, events[2].Status)
+	assert.True(t, events[3].Final)
+	assert.Equal(t, 3, events[3].Attempt)
+	assert.NoError(t, events[3].Err)
+}
+
+// newBrokenScreenshotClient returns a client of which screenshot response is broken in the middle only once.
+func newBrokenScreenshotClient(t *testing.T, count *int32) urlscan.Client {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		if atomic.AddInt32(count, 1) == 1 {
+			// Close connection in the middle of the body.
+			w.Header().Set("Content-Length", strconv.Itoa(len(testPNG)*2))
+			w.Write(testPNG)
+			w.(http.Flusher).Flush()
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			conn.Close()
+			return
+		}
+		w.Write(testPNG)
+	}))
+	urlscan.WithRetryPolicy(urlscan.ExponentialRetryPolicy{BaseDelay: time.Millisecond})(&client)
+	return client
+}
+
+func TestTaskScreenshotBrokenBody(t *testing.T) {
+	var count int32
+	client := newBrokenScreenshotClient(t, &count)
+	task := client.ResultTask(testScanUUID)
+
+	// Image in memory is downloaded again from the beginning.
+	img, contentType, err := task.Screenshot(context.Background(), fastWait...)
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+	assert.Equal(t, testPNG, img)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&count))
+}
+
+func TestTaskScreenshotToBrokenBody(t *testing.T) {
+	var count int32
+	client := newBrokenScreenshotClient(t, &count)
+	task := client.ResultTask(testScanUUID)
+
+	// Streamed image is not retried after a part of it is written.
+	var buf bytes.Buffer
+	_, err := task.ScreenshotTo(context.Background(), &buf, fastWait...)
+	require.Error(t, err)
+	assert.Equal(t, testPNG, buf.Bytes())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&count))
+}
+
+func TestTaskScreenshotURL(t *testing.T) {
+	var paths []string
+	var mutex sync.Mutex
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		paths = append(paths, r.URL.Path)
+		mutex.Unlock()
+
+		switch {
+		case r.URL.Path == "/search/":
+			fmt.Fprintf(w, `{"results":[{"_id":"%s","screenshot":"http://%s/search-img/%s.png"}],"total":1}`, testScanUUID, r.Host, testScanUUID)
+		case strings.HasPrefix(r.URL.Path, "/result/"):
+			fmt.Fprintf(w, `{"task":{"uuid":"%s","screenshotURL":"http://%s/result-img/%s.png"}}`, testScanUUID, r.Host, testScanUUID)
+		default:
+			// API key is sent to host of BaseURL.
+			assert.Equal(t, "test-api-key", r.Header.Get("API-Key"))
+			w.Write(testPNG)
+		}
+	}))
+
+	resp, err := client.Search(urlscan.SearchArguments{})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(resp.Results))
+
+	// Screenshot of search result is used.
+	task := resp.Results[0].Task()
+	img, _, err := task.Screenshot(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, testPNG, img)
+
+	// Screenshot of scan result is preferred.
+	require.NoError(t, task.Get())
+	_, _, err = task.Screenshot(context.Background())
+	require.NoError(t, err)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, []string{
+		"/search/",
+		"/search-img/" + testScanUUID + ".png",
+		"/result/" + testScanUUID + "/",
+		"/result-img/" + testScanUUID + ".png",
+	}, paths)
+}
+
+func TestTaskScreenshotOtherHost(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "", r.Header.Get("API-Key"))
+		w.Write(testPNG)
+	}))
+	defer other.Close()
+
+	var count int32
+	client := newScreenshotClient(t, 0, &count)
+
+	var task urlscan.Task
+	require.NoError(t, json.Unmarshal([]byte(`{"uuid":"`+testScanUUID+`","screenshot_url":"`+other.URL+`/x.png"}`), &task))
+	client.AttachTask(&task)
+
+	img, _, err := task.Screenshot(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, testPNG, img)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&count))
+}
+
+// notifyWriter closes written at the first Write.
+type notifyWriter struct {
+	buf     bytes.Buffer
+	written chan struct{}
+}
+
+func (x *notifyWriter) Write(p []byte) (int, error) {
+	if x.buf.Len() == 0 {
+		close(x.written)
+	}
+	return x.buf.Write(p)
+}
+
+func TestClientScreenshotToStreams(t *testing.T) {
+	w := &notifyWriter{written: make(chan struct{})}
+	client := newTestClient(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "image/png")
+		rw.Write(testPNG[:4])
+		rw.(http.Flusher).Flush()
+
+		// Rest of the image is sent after the head is written into w.
+		select {
+		case <-w.written:
+		case <-time.After(5 * time.Second):
+			t.Error("image is not streamed")
+		}
+		rw.Write(testPNG[4:])
+	}))
+
+	_, err := client.ScreenshotTo(context.Background(), testScanUUID, w)
+	require.NoError(t, err)
+	assert.Equal(t, testPNG, w.buf.Bytes())
+}
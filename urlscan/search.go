@@ -1,64 +1,158 @@
 package urlscan
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
-
-	"github.com/pkg/errors"
+	"strconv"
+	"strings"
 )
 
 // SearchArguments is input data structure of Search()
 type SearchArguments struct {
 	// Optional. urlscan.io search query.
-	// See Help & Example of https://urlscan.io/search/ for more detail
+	// See Help & Example of https://urlscan.io/search/ for more detail. It can be built by query package.
 	Query *string `json:"query"`
 	// Optional. Page size
 	Size *uint64 `json:"size"`
 	// Optional. specificied via $sort_field:$sort_order. Default: _score
 	Sort *string `json:"sort"`
+	// Optional. Sort values of the last result of previous page, e.g. "1588918236123,ba1d4d33-2a0c-4ad8-88f4-7fbd5a0e2a9f".
+	// It can be built from SearchResult.Sort by SearchAfter().
+	SearchAfter *string `json:"search_after"`
 }
 
 // SearchResult represents a single search result from the API
 type SearchResult struct {
-	ID   string `json:"_id"`
-	Page struct {
-		Asn     string `json:"asn"`
-		Asnname string `json:"asnname"`
-		City    string `json:"city"`
-		Country string `json:"country"`
-		Domain  string `json:"domain"`
-		IP      string `json:"ip"`
-		Ptr     string `json:"ptr"`
-		Server  string `json:"server"`
-		URL     string `json:"url"`
-	} `json:"page"`
+	ID    string     `json:"_id"`
+	Score float64    `json:"_score"`
+	Page  SearchPage `json:"page"`
+	// Result is URL of result API of the scan.
 	Result string `json:"result"`
-	Stats  struct {
-		ConsoleMsgs       int64 `json:"consoleMsgs"`
-		DataLength        int64 `json:"dataLength"`
-		EncodedDataLength int64 `json:"encodedDataLength"`
-		Requests          int64 `json:"requests"`
-		UniqIPs           int64 `json:"uniqIPs"`
-	} `json:"stats"`
-	Task struct {
-		Method     string `json:"method"`
-		Source     string `json:"source"`
-		Time       string `json:"time"`
-		URL        string `json:"url"`
-		Visibility string `json:"visibility"`
-	} `json:"task"`
-	UniqCountries int64 `json:"uniq_countries"`
+	// Screenshot is URL of screenshot image of the page.
+	Screenshot string      `json:"screenshot"`
+	Stats      SearchStats `json:"stats"`
+	// TaskInfo is submission of the scan. Use Task() to get the scan result.
+	TaskInfo      SearchTask     `json:"task"`
+	Verdicts      SearchVerdicts `json:"verdicts"`
+	Brand         []Brand        `json:"brand"`
+	UniqCountries int64          `json:"uniq_countries"`
+	// Sort is sort values of the result. It is used to get next page by SearchArguments.SearchAfter.
+	Sort []interface{} `json:"sort"`
+
+	client *Client
+}
+
+// SearchPage is the scanned page of SearchResult.
+type SearchPage struct {
+	ApexDomain   string   `json:"apexDomain"`
+	Asn          string   `json:"asn"`
+	Asnname      string   `json:"asnname"`
+	City         string   `json:"city"`
+	Country      string   `json:"country"`
+	Domain       string   `json:"domain"`
+	IP           string   `json:"ip"`
+	MimeType     string   `json:"mimeType"`
+	Ptr          string   `json:"ptr"`
+	Server       string   `json:"server"`
+	Status       string   `json:"status"`
+	Title        string   `json:"title"`
+	TLSAgeDays   int64    `json:"tlsAgeDays"`
+	TLSIssuer    string   `json:"tlsIssuer"`
+	TLSValidDays int64    `json:"tlsValidDays"`
+	TLSValidFrom DateTime `json:"tlsValidFrom"`
+	UmbrellaRank int64    `json:"umbrellaRank"`
+	URL          string   `json:"url"`
+}
+
+// SearchStats is statistics of requests in the page of SearchResult.
+type SearchStats struct {
+	ConsoleMsgs       int64 `json:"consoleMsgs"`
+	DataLength        int64 `json:"dataLength"`
+	EncodedDataLength int64 `json:"encodedDataLength"`
+	Requests          int64 `json:"requests"`
+	UniqCountries     int64 `json:"uniqCountries"`
+	UniqIPs           int64 `json:"uniqIPs"`
+}
+
+// SearchTask is submission of the scan of SearchResult.
+type SearchTask struct {
+	ApexDomain string   `json:"apexDomain"`
+	Domain     string   `json:"domain"`
+	Method     string   `json:"method"`
+	Source     string   `json:"source"`
+	Tags       []string `json:"tags"`
+	Time       DateTime `json:"time"`
+	URL        string   `json:"url"`
+	UUID       string   `json:"uuid"`
+	Visibility string   `json:"visibility"`
+}
+
+// SearchVerdicts is overall verdicts of the scan of SearchResult.
+type SearchVerdicts struct {
+	Score     int64 `json:"score"`
+	Malicious bool  `json:"malicious"`
+}
+
+// Task returns Task of the scan to get full result by Get(). The Task is bound to Client that searched the result,
+// otherwise Client.AttachTask is required. Task.Screenshot downloads x.Screenshot.
+func (x SearchResult) Task() Task {
+	uuid := x.TaskInfo.UUID
+	if uuid == "" {
+		uuid = x.ID
+	}
+
+	task := newResultTask(DefaultBaseURL, uuid)
+	if x.client != nil {
+		task = x.client.ResultTask(uuid)
+	}
+	task.screenshot = x.Screenshot
+	return task
+}
+
+// SearchAfter returns a value of SearchArguments.SearchAfter to get results after x.
+func (x SearchResult) SearchAfter() string {
+	values := make([]string, len(x.Sort))
+	for i, v := range x.Sort {
+		switch v := v.(type) {
+		case float64:
+			values[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			values[i] = fmt.Sprint(v)
+		}
+	}
+	return strings.Join(values, ",")
 }
 
 // SearchResponse is returned by Search() and including existing scan results.
 type SearchResponse struct {
 	Results []SearchResult `json:"results"`
 	Total   int64          `json:"total"`
+	// HasMore is true if there are more results after this page.
+	HasMore bool `json:"has_more"`
 }
 
 // Search sends query to search existing scan results with query
 func (x *Client) Search(args SearchArguments) (SearchResponse, error) {
+	return x.SearchContext(context.Background(), args)
+}
+
+// SearchContext is same with Search, but the request is bound to ctx.
+func (x *Client) SearchContext(ctx context.Context, args SearchArguments) (SearchResponse, error) {
 	var result SearchResponse
+	if _, err := x.get(ctx, "search", searchValues(args), &result); err != nil {
+		return result, err
+	}
+	for i := range result.Results {
+		result.Results[i].client = x
+	}
+
+	return result, nil
+}
+
+func searchValues(args SearchArguments) url.Values {
 	values := make(url.Values)
 
 	if args.Query != nil {
@@ -70,14 +164,275 @@ func (x *Client) Search(args SearchArguments) (SearchResponse, error) {
 	if args.Sort != nil {
 		values.Add("sort", *args.Sort)
 	}
+	if args.SearchAfter != nil {
+		values.Add("search_after", *args.SearchAfter)
+	}
+	return values
+}
+
+// DefaultSearchMaxResults is maximum number of results returned by SearchIterator.
+const DefaultSearchMaxResults = 10000
+
+// searchConfig is a configuration shared by SearchIter and SearchStream.
+type searchConfig struct {
+	maxResults int
+}
+
+func newSearchConfig(opts []SearchIterOption) searchConfig {
+	cfg := searchConfig{maxResults: DefaultSearchMaxResults}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// reached returns true if count results reach maxResults.
+func (x searchConfig) reached(count int) bool {
+	return x.maxResults > 0 && count >= x.maxResults
+}
+
+// nextSearchAfter returns search_after for next page. It returns false if no more page is needed or it can not be requested.
+func (x searchConfig) nextSearchAfter(hasMore bool, last SearchResult, count int) (string, bool) {
+	if !hasMore || len(last.Sort) == 0 || x.reached(count) {
+		return "", false
+	}
+	return last.SearchAfter(), true
+}
+
+// SearchIterOption is an option of SearchIter and SearchStream.
+type SearchIterOption func(*searchConfig)
+
+// SearchMaxResults sets maximum number of results returned by SearchIterator. 0 or less means no limit.
+// Default is DefaultSearchMaxResults.
+func SearchMaxResults(n int) SearchIterOption {
+	return func(x *searchConfig) {
+		x.maxResults = n
+	}
+}
+
+// SearchIterator walks all pages of search results with search_after. Use it like:
+//
+//	iter := client.SearchIter(ctx, args)
+//	for iter.Next() {
+//		result := iter.Result()
+//	}
+//	if err := iter.Err(); err != nil {
+//		...
+//	}
+type SearchIterator struct {
+	client *Client
+	ctx    context.Context
+	args   SearchArguments
+	cfg    searchConfig
+
+	page    []SearchResult
+	current SearchResult
+	count   int
+	total   int64
+	done    bool
+	err     error
+}
 
-	code, err := x.get("search", values, &result)
+// SearchIter returns SearchIterator for args. Pages are requested lazily by Next.
+func (x *Client) SearchIter(ctx context.Context, args SearchArguments, opts ...SearchIterOption) *SearchIterator {
+	return &SearchIterator{
+		client: x,
+		ctx:    ctx,
+		args:   args,
+		cfg:    newSearchConfig(opts),
+	}
+}
+
+// Next advances to next result. It returns false when all results are returned, the limit is reached or an error occurred.
+func (x *SearchIterator) Next() bool {
+	if x.err != nil || x.cfg.reached(x.count) {
+		return false
+	}
+
+	if len(x.page) == 0 {
+		if x.done {
+			return false
+		}
+		if err := x.fetch(); err != nil {
+			x.err = err
+			return false
+		}
+		if len(x.page) == 0 {
+			return false
+		}
+	}
+
+	x.current, x.page = x.page[0], x.page[1:]
+	x.count++
+	return true
+}
+
+func (x *SearchIterator) fetch() error {
+	resp, err := x.client.SearchContext(x.ctx, x.args)
 	if err != nil {
-		return result, err
+		return err
+	}
+
+	x.page = resp.Results
+	x.total = resp.Total
+
+	var last SearchResult
+	if len(resp.Results) > 0 {
+		last = resp.Results[len(resp.Results)-1]
+	}
+	after, ok := x.cfg.nextSearchAfter(resp.HasMore, last, x.count+len(resp.Results))
+	if !ok {
+		x.done = true
+		return nil
 	}
-	if code != 200 {
-		return result, errors.Errorf("Unexpected status code: %d", code)
+	x.args.SearchAfter = String(after)
+	return nil
+}
+
+// Result returns current result moved by Next.
+func (x *SearchIterator) Result() SearchResult {
+	return x.current
+}
+
+// Total returns total number of hits reported by the last page.
+func (x *SearchIterator) Total() int64 {
+	return x.total
+}
+
+// Err returns an error that stopped iteration, if any.
+func (x *SearchIterator) Err() error {
+	return x.err
+}
+
+// searchPage decodes a page of search API incrementally. Results over limit are discarded without keeping them.
+type searchPage struct {
+	client  *Client
+	limit   int
+	results []SearchResult
+	hasMore bool
+}
+
+func (x *searchPage) decodeStream(resp *http.Response) error {
+	// The page may be decoded again by retry of the request.
+	x.results, x.hasMore = nil, false
+
+	body := &bodyReader{r: resp.Body}
+	if err := x.decode(json.NewDecoder(body)); err != nil {
+		if body.err != nil {
+			// Broken connection while reading the page can be retried by RetryPolicy as other transport errors.
+			return newNetworkError(resp.Request, body.err)
+		}
+		return err
 	}
+	return nil
+}
+
+func (x *searchPage) decode(dec *json.Decoder) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch token {
+		case "results":
+			if err := x.decodeResults(dec); err != nil {
+				return err
+			}
+		case "has_more":
+			if err := dec.Decode(&x.hasMore); err != nil {
+				return err
+			}
+		default:
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (x *searchPage) decodeResults(dec *json.Decoder) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		var result SearchResult
+		if err := dec.Decode(&result); err != nil {
+			return err
+		}
+		if x.limit > 0 && len(x.results) >= x.limit {
+			continue
+		}
+		result.client = x.client
+		x.results = append(x.results, result)
+	}
+
+	_, err := dec.Token()
+	return err
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("Unexpected token %v, expected %v", token, delim)
+	}
+	return nil
+}
+
+// SearchStream walks all pages of search results like SearchIter and sends results to the returned channel.
+// Each page is decoded and its response body is closed before the results are sent, so a slow consumer does not
+// keep the connection open nor hit timeout of the client.
+// The error channel gets at most one error and both channels are closed when the search finished, failed or ctx is done.
+func (x *Client) SearchStream(ctx context.Context, args SearchArguments, opts ...SearchIterOption) (<-chan SearchResult, <-chan error) {
+	ch := make(chan SearchResult)
+	errCh := make(chan error, 1)
+	cfg := newSearchConfig(opts)
+
+	go func() {
+		defer close(errCh)
+		defer close(ch)
+
+		var count int
+		for {
+			page := &searchPage{client: x}
+			if cfg.maxResults > 0 {
+				page.limit = cfg.maxResults - count
+			}
+			if _, err := x.get(ctx, "search", searchValues(args), page); err != nil {
+				errCh <- err
+				return
+			}
+
+			var last SearchResult
+			for _, result := range page.results {
+				select {
+				case ch <- result:
+				case <-ctx.Done():
+					errCh <- ctx.Err()
+					return
+				}
+				last = result
+				count++
+			}
+
+			after, ok := cfg.nextSearchAfter(page.hasMore, last, count)
+			if !ok {
+				return
+			}
+			args.SearchAfter = String(after)
+		}
+	}()
 
-	return result, err
+	return ch, errCh
 }
@@ -1,14 +1,27 @@
 package urlscan_test
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/m-mizutani/urlscan-go/urlscan"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestSearch(t *testing.T) {
+	requireAPIKey(t)
+
 	client := urlscan.NewClient(cfg.ApiKey)
 
 	resp, err := client.Search(urlscan.SearchArguments{
@@ -21,6 +34,8 @@ func TestSearch(t *testing.T) {
 }
 
 func TestSearchSize(t *testing.T) {
+	requireAPIKey(t)
+
 	client := urlscan.NewClient(cfg.ApiKey)
 
 	resp, err := client.Search(urlscan.SearchArguments{
@@ -31,3 +46,279 @@ func TestSearchSize(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 1, len(resp.Results))
 }
+
+// queryRecorder records queries received by test server goroutines.
+type queryRecorder struct {
+	mutex   sync.Mutex
+	queries []url.Values
+}
+
+func (x *queryRecorder) add(q url.Values) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	x.queries = append(x.queries, q)
+}
+
+func (x *queryRecorder) list() []url.Values {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	return append([]url.Values{}, x.queries...)
+}
+
+// newSearchServer returns a client of which search API returns n results by pages of size.
+func newSearchServer(t *testing.T, n, size int, queries *queryRecorder) urlscan.Client {
+	return newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if queries != nil {
+			queries.add(q)
+		}
+
+		start := 0
+		if after := q.Get("search_after"); after != "" {
+			parts := strings.Split(after, ",")
+			i, err := strconv.Atoi(parts[0])
+			if err != nil {
+				t.Error(err)
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			start = i + 1
+		}
+
+		var results []string
+		for i := start; i < n && i < start+size; i++ {
+			results = append(results, fmt.Sprintf(`{"_id":"id-%d","sort":[%d,"id-%d"]}`, i, i, i))
+		}
+		fmt.Fprintf(w, `{"results":[%s],"total":%d,"has_more":%v}`, strings.Join(results, ","), n, start+size < n)
+	}))
+}
+
+func TestSearchAfter(t *testing.T) {
+	var queries queryRecorder
+	client := newSearchServer(t, 5, 2, &queries)
+
+	resp, err := client.Search(urlscan.SearchArguments{
+		Query:       urlscan.String("domain:example.com"),
+		SearchAfter: urlscan.String("1,id-1"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(resp.Results))
+	assert.True(t, resp.HasMore)
+	assert.Equal(t, "id-2", resp.Results[0].ID)
+	assert.Equal(t, "3,id-3", resp.Results[1].SearchAfter())
+	assert.Equal(t, "1,id-1", queries.list()[0].Get("search_after"))
+}
+
+func TestSearchIter(t *testing.T) {
+	var queries queryRecorder
+	client := newSearchServer(t, 5, 2, &queries)
+
+	iter := client.SearchIter(context.Background(), urlscan.SearchArguments{Query: urlscan.String("domain:example.com")})
+	var ids []string
+	for iter.Next() {
+		ids = append(ids, iter.Result().ID)
+	}
+	require.NoError(t, iter.Err())
+	assert.Equal(t, []string{"id-0", "id-1", "id-2", "id-3", "id-4"}, ids)
+	assert.Equal(t, int64(5), iter.Total())
+	assert.Equal(t, 3, len(queries.list()))
+	assert.Equal(t, "", queries.list()[0].Get("search_after"))
+	assert.Equal(t, "3,id-3", queries.list()[2].Get("search_after"))
+}
+
+func TestSearchIterMaxResults(t *testing.T) {
+	var queries queryRecorder
+	client := newSearchServer(t, 10, 2, &queries)
+
+	iter := client.SearchIter(context.Background(), urlscan.SearchArguments{}, urlscan.SearchMaxResults(3))
+	count := 0
+	for iter.Next() {
+		count++
+	}
+	require.NoError(t, iter.Err())
+	assert.Equal(t, 3, count)
+	assert.Equal(t, 2, len(queries.list()))
+}
+
+func TestSearchIterError(t *testing.T) {
+	client := newErrorClient(t, 400, nil, `{"message":"Invalid query","status":400}`)
+
+	iter := client.SearchIter(context.Background(), urlscan.SearchArguments{Query: urlscan.String("???")})
+	assert.False(t, iter.Next())
+	assert.Error(t, iter.Err())
+}
+
+func collectSearchStream(ch <-chan urlscan.SearchResult, errCh <-chan error) ([]string, error) {
+	var ids []string
+	for result := range ch {
+		ids = append(ids, result.ID)
+	}
+	return ids, <-errCh
+}
+
+func TestSearchStream(t *testing.T) {
+	var queries queryRecorder
+	client := newSearchServer(t, 5, 2, &queries)
+
+	ids, err := collectSearchStream(client.SearchStream(context.Background(), urlscan.SearchArguments{}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id-0", "id-1", "id-2", "id-3", "id-4"}, ids)
+	assert.Equal(t, 3, len(queries.list()))
+	assert.Equal(t, "3,id-3", queries.list()[2].Get("search_after"))
+}
+
+func TestSearchStreamMaxResults(t *testing.T) {
+	var queries queryRecorder
+	client := newSearchServer(t, 10, 4, &queries)
+
+	ids, err := collectSearchStream(client.SearchStream(context.Background(), urlscan.SearchArguments{}, urlscan.SearchMaxResults(5)))
+	require.NoError(t, err)
+	assert.Equal(t, 5, len(ids))
+	assert.Equal(t, 2, len(queries.list()))
+}
+
+func TestSearchStreamCancel(t *testing.T) {
+	var queries queryRecorder
+	client := newSearchServer(t, 1000, 100, &queries)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, errCh := client.SearchStream(ctx, urlscan.SearchArguments{})
+
+	first := <-ch
+	assert.Equal(t, "id-0", first.ID)
+	cancel()
+
+	_, err := collectSearchStream(ch, errCh)
+	assert.True(t, errors.Is(err, context.Canceled))
+	// Next page must not be requested after the consumer gave up.
+	assert.Equal(t, 1, len(queries.list()))
+}
+
+func TestSearchStreamSlowConsumer(t *testing.T) {
+	client := newSearchServer(t, 500, 500, nil)
+	urlscan.WithTimeout(100 * time.Millisecond)(&client)
+
+	ch, errCh := client.SearchStream(context.Background(), urlscan.SearchArguments{}, urlscan.SearchMaxResults(0))
+	count := 0
+	for range ch {
+		// Response body must not be kept open while the consumer is busy.
+		time.Sleep(time.Millisecond)
+		count++
+	}
+	require.NoError(t, <-errCh)
+	assert.Equal(t, 500, count)
+}
+
+func TestSearchStreamError(t *testing.T) {
+	client := newErrorClient(t, 400, nil, `{"message":"Invalid query","status":400}`)
+
+	ids, err := collectSearchStream(client.SearchStream(context.Background(), urlscan.SearchArguments{}))
+	assert.Equal(t, 0, len(ids))
+	assert.Error(t, err)
+}
+
+const testSearchResponse = `{
+  "results": [{
+    "_id": "` + testScanUUID + `",
+    "_score": null,
+    "task": {"visibility": "public", "method": "api", "domain": "example.com", "apexDomain": "example.com",
+      "time": "2020-05-08T06:10:36.123Z", "uuid": "` + testScanUUID + `", "url": "https://example.com/", "tags": ["phishing"]},
+    "stats": {"uniqIPs": 2, "uniqCountries": 1, "dataLength": 1024, "encodedDataLength": 512, "requests": 3},
+    "page": {"country": "US", "server": "ECS", "ip": "192.0.2.1", "mimeType": "text/html", "title": "Example Domain",
+      "url": "https://example.com/", "tlsValidDays": 365, "tlsAgeDays": 10, "tlsValidFrom": "2020-04-28T00:00:00.000Z",
+      "domain": "example.com", "apexDomain": "example.com", "asnname": "EDGECAST, US", "asn": "AS15133",
+      "tlsIssuer": "DigiCert SHA2 Secure Server CA", "status": "200"},
+    "verdicts": {"score": 80, "malicious": true},
+    "brand": [{"key": "example", "name": "Example Inc.", "country": ["US"], "vertical": ["Technology"]}],
+    "screenshot": "https://urlscan.io/screenshots/` + testScanUUID + `.png",
+    "result": "https://urlscan.io/api/v1/result/` + testScanUUID + `/",
+    "sort": [1588918236123, "` + testScanUUID + `"]
+  }],
+  "total": 1,
+  "has_more": false
+}`
+
+func TestSearchResultModel(t *testing.T) {
+	var resultPath string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/search") {
+			w.Write([]byte(testSearchResponse))
+			return
+		}
+		resultPath = r.URL.Path
+		w.Write([]byte(`{"task":{"uuid":"` + testScanUUID + `"}}`))
+	}))
+
+	resp, err := client.Search(urlscan.SearchArguments{})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(resp.Results))
+
+	result := resp.Results[0]
+	assert.Equal(t, time.Date(2020, 5, 8, 6, 10, 36, 123000000, time.UTC), result.TaskInfo.Time.Time().UTC())
+	assert.Equal(t, []string{"phishing"}, result.TaskInfo.Tags)
+	assert.Equal(t, testScanUUID, result.TaskInfo.UUID)
+	assert.Equal(t, "example.com", result.TaskInfo.Domain)
+	assert.Equal(t, "200", result.Page.Status)
+	assert.Equal(t, "text/html", result.Page.MimeType)
+	assert.Equal(t, "Example Domain", result.Page.Title)
+	assert.Equal(t, int64(365), result.Page.TLSValidDays)
+	assert.Equal(t, 2020, result.Page.TLSValidFrom.Time().Year())
+	assert.Equal(t, "DigiCert SHA2 Secure Server CA", result.Page.TLSIssuer)
+	assert.Equal(t, int64(1), result.Stats.UniqCountries)
+	assert.True(t, result.Verdicts.Malicious)
+	assert.Equal(t, int64(80), result.Verdicts.Score)
+	require.Equal(t, 1, len(result.Brand))
+	assert.Equal(t, "Example Inc.", result.Brand[0].Name)
+	assert.Contains(t, result.Screenshot, ".png")
+	assert.Equal(t, "1588918236123,"+testScanUUID, result.SearchAfter())
+
+	task := result.Task()
+	assert.Equal(t, testScanUUID, task.UUID())
+	require.NoError(t, task.Get())
+	assert.Equal(t, "/result/"+testScanUUID+"/", resultPath)
+}
+
+func TestSearchResultTaskNotAttached(t *testing.T) {
+	var result urlscan.SearchResult
+	require.NoError(t, json.Unmarshal([]byte(`{"_id":"`+testScanUUID+`"}`), &result))
+
+	task := result.Task()
+	assert.Equal(t, testScanUUID, task.UUID())
+	assert.True(t, errors.Is(task.Get(), urlscan.ErrTaskNotAttached))
+}
+
+func TestSearchResultLenientTime(t *testing.T) {
+	var result urlscan.SearchResult
+	require.NoError(t, json.Unmarshal([]byte(`{"_id":"x","page":{"tlsValidFrom":"2020-01-02"},"task":{"time":"unknown"}}`), &result))
+	assert.Equal(t, 2020, result.Page.TLSValidFrom.Time().Year())
+	assert.True(t, result.TaskInfo.Time.Time().IsZero())
+	assert.Equal(t, urlscan.DateTime("unknown"), result.TaskInfo.Time)
+}
+
+func TestSearchStreamRetryBrokenPage(t *testing.T) {
+	var count int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := `{"results":[{"_id":"a"},{"_id":"b"}],"total":2,"has_more":false}`
+		if atomic.AddInt32(&count, 1) == 1 {
+			// Close connection after both results are sent but before the end of the page.
+			w.Header().Set("Content-Length", strconv.Itoa(len(page)))
+			w.Write([]byte(page[:strings.Index(page, `"total"`)]))
+			w.(http.Flusher).Flush()
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			conn.Close()
+			return
+		}
+		w.Write([]byte(page))
+	}))
+	urlscan.WithRetryPolicy(urlscan.ExponentialRetryPolicy{BaseDelay: time.Millisecond})(&client)
+
+	ids, err := collectSearchStream(client.SearchStream(context.Background(), urlscan.SearchArguments{}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, ids)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&count))
+}
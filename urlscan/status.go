@@ -0,0 +1,352 @@
+package urlscan
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TaskStatus is status of a submitted scan.
+type TaskStatus int
+
+// Statuses of a scan.
+const (
+	// TaskStatusUnknown means status could not be determined, e.g. by network error.
+	TaskStatusUnknown TaskStatus = iota
+	// TaskStatusPending means the scan is still running.
+	TaskStatusPending
+	// TaskStatusDone means the scan is completed and result is available.
+	TaskStatusDone
+	// TaskStatusFailed means the scan was finished without result.
+	TaskStatusFailed
+	// TaskStatusNotFound means urlscan.io does not know the scan. It happens for a while just after submission as well.
+	TaskStatusNotFound
+	// TaskStatusDeleted means the result was deleted.
+	TaskStatusDeleted
+	// TaskStatusBlocked means urlscan.io refused to scan the URL.
+	TaskStatusBlocked
+)
+
+func (x TaskStatus) String() string {
+	switch x {
+	case TaskStatusPending:
+		return "pending"
+	case TaskStatusDone:
+		return "done"
+	case TaskStatusFailed:
+		return "failed"
+	case TaskStatusNotFound:
+		return "not found"
+	case TaskStatusDeleted:
+		return "deleted"
+	case TaskStatusBlocked:
+		return "blocked"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (x TaskStatus) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (x *TaskStatus) UnmarshalText(text []byte) error {
+	for s := TaskStatusUnknown; s <= TaskStatusBlocked; s++ {
+		if s.String() == string(text) {
+			*x = s
+			return nil
+		}
+	}
+	return errors.Errorf("Unknown task status: %s", text)
+}
+
+// Finished returns true if the status will not change anymore.
+func (x TaskStatus) Finished() bool {
+	switch x {
+	case TaskStatusDone, TaskStatusFailed, TaskStatusDeleted, TaskStatusBlocked:
+		return true
+	}
+	return false
+}
+
+// ErrWaitTimeout is returned (wrapped) when a scan is not finished within the retry limit.
+var ErrWaitTimeout = errors.New("Timeout of waiting scan result")
+
+// DefaultWaitMaxRetry is a number of attempts to get result by Wait.
+const DefaultWaitMaxRetry = 30
+
+// statusOf converts an error of result API to TaskStatus. It returns TaskStatusUnknown if err is not *APIError.
+func statusOf(err error) TaskStatus {
+	if err == nil {
+		return TaskStatusDone
+	}
+
+	apiErr, ok := asAPIError(err)
+	if !ok {
+		return TaskStatusUnknown
+	}
+
+	switch apiErr.StatusCode {
+	case http.StatusNotFound:
+		msg := strings.ToLower(apiErr.Message)
+		if strings.Contains(msg, "not finished") || strings.Contains(msg, "not yet") {
+			return TaskStatusPending
+		}
+		return TaskStatusNotFound
+	case http.StatusGone:
+		return TaskStatusDeleted
+	case http.StatusBadRequest:
+		if IsBlockedURL(err) {
+			return TaskStatusBlocked
+		}
+		return TaskStatusFailed
+	default:
+		return TaskStatusUnknown
+	}
+}
+
+// status requests result of the scan once. Result is stored into x.Result when the scan is done.
+// The returned error is set if status is not TaskStatusDone.
+func (x *Task) status(ctx context.Context) (TaskStatus, int, error) {
+	code, err := x.getResult(ctx)
+	return statusOf(err), code, err
+}
+
+// Status requests result of the scan once and returns status of the scan. x.Result is filled if TaskStatusDone is returned.
+// The error is returned only when status can not be determined, e.g. network error or 5xx response.
+func (x *Task) Status(ctx context.Context) (TaskStatus, error) {
+	status, _, err := x.status(ctx)
+	if status == TaskStatusUnknown {
+		return status, errors.Wrap(err, "Fail to get result query")
+	}
+	return status, nil
+}
+
+// WaitResult describes how Await finished.
+type WaitResult struct {
+	// Status is the last known status of the scan.
+	Status TaskStatus
+	// Attempts is a number of requests to result API.
+	Attempts int
+	// Elapsed is duration from start of Await to the end.
+	Elapsed time.Duration
+}
+
+// WaitOption is an option of Await.
+type WaitOption func(*waitConfig)
+
+type waitConfig struct {
+	maxRetry     int
+	backoff      Backoff
+	initialDelay time.Duration
+	onPoll       func(PollEvent)
+	throttle     *throttle
+}
+
+func defaultWaitConfig() waitConfig {
+	return waitConfig{
+		maxRetry: DefaultWaitMaxRetry,
+		backoff:  DefaultBackoff,
+	}
+}
+
+// newWaitConfig applies opts to wait configuration of client.
+func newWaitConfig(client *Client, opts []WaitOption) *waitConfig {
+	cfg := defaultWaitConfig()
+	if client != nil && client.wait != nil {
+		cfg = *client.wait
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.backoff == nil {
+		cfg.backoff = DefaultBackoff
+	}
+	if cfg.onPoll == nil {
+		cfg.onPoll = func(PollEvent) {}
+	}
+	return &cfg
+}
+
+// WaitMaxRetry sets maximum number of requests to result API. Default is DefaultWaitMaxRetry.
+func WaitMaxRetry(maxRetry int) WaitOption {
+	return func(x *waitConfig) {
+		x.maxRetry = maxRetry
+	}
+}
+
+// WaitBackoff sets Backoff between requests to result API. Default is DefaultBackoff.
+func WaitBackoff(backoff Backoff) WaitOption {
+	return func(x *waitConfig) {
+		x.backoff = backoff
+	}
+}
+
+// WaitInitialDelay sets delay before the first request to result API. urlscan.io recommends
+// waiting at least 10 seconds after submission because the scan is not finished before that.
+func WaitInitialDelay(delay time.Duration) WaitOption {
+	return func(x *waitConfig) {
+		x.initialDelay = delay
+	}
+}
+
+// WaitOnPoll sets a callback called after each request to result API and once more when Await finishes.
+// The callback is called synchronously in the polling loop, so it should return quickly.
+func WaitOnPoll(onPoll func(PollEvent)) WaitOption {
+	return func(x *waitConfig) {
+		x.onPoll = onPoll
+	}
+}
+
+// WithWaitOptions sets default WaitOption of Wait and Await for Tasks of the Client.
+func WithWaitOptions(opts ...WaitOption) Option {
+	return func(x *Client) {
+		x.wait = newWaitConfig(x, opts)
+	}
+}
+
+// Await polls result API until the scan is finished, and returns WaitResult with nil error if the scan is done.
+// If the scan is failed, deleted or blocked, it returns an error with the final status in WaitResult.
+// 5xx and 429 responses are retried by polling, while other 4xx responses (e.g. invalid API key) and network errors stop it.
+func (x *Task) Await(ctx context.Context, opts ...WaitOption) (WaitResult, error) {
+	cfg := newWaitConfig(x.client, opts)
+
+	start := time.Now()
+	result, err := x.poll(ctx, cfg, start)
+
+	final := PollEvent{
+		Attempt: result.Attempts,
+		Status:  result.Status,
+		Elapsed: time.Since(start),
+		Final:   true,
+		Err:     err,
+	}
+	if result.Status == TaskStatusDone {
+		scanResult := x.Result
+		final.Result = &scanResult
+	}
+	cfg.onPoll(final)
+
+	return result, err
+}
+
+func (x *Task) poll(ctx context.Context, cfg *waitConfig, start time.Time) (WaitResult, error) {
+	var result WaitResult
+	delay := cfg.initialDelay
+
+	for i := 0; i < cfg.maxRetry; i++ {
+		if delay > 0 {
+			if err := sleepContext(ctx, delay); err != nil {
+				result.Elapsed = time.Since(start)
+				return result, errors.Wrap(err, "Interrupted while waiting result")
+			}
+		}
+
+		if err := cfg.throttle.wait(ctx); err != nil {
+			result.Elapsed = time.Since(start)
+			return result, errors.Wrap(err, "Interrupted while waiting result")
+		}
+
+		status, code, err := x.status(ctx)
+		result.Attempts++
+		if status != TaskStatusUnknown {
+			// Keep the last known status if the request failed.
+			result.Status = status
+		}
+		result.Elapsed = time.Since(start)
+
+		retry := status == TaskStatusPending || status == TaskStatusNotFound
+		if status == TaskStatusUnknown {
+			// Only server side failure and rate limit are retried. Network error and other 4xx such as
+			// invalid API key are returned immediately because they are not solved by waiting.
+			if apiErr, ok := asAPIError(err); ok {
+				retry = apiErr.StatusCode >= 500 || apiErr.StatusCode == http.StatusTooManyRequests
+			}
+		}
+
+		delay = 0
+		if retry && i+1 < cfg.maxRetry {
+			delay = cfg.backoff.Delay(i + 1)
+		}
+		cfg.onPoll(PollEvent{
+			Attempt:    result.Attempts,
+			StatusCode: code,
+			Status:     status,
+			NextDelay:  delay,
+			Elapsed:    result.Elapsed,
+		})
+
+		switch {
+		case status == TaskStatusDone:
+			return result, nil
+		case retry:
+			continue
+		case status == TaskStatusUnknown:
+			return result, errors.Wrap(err, "Fail to get result query")
+		default:
+			return result, errors.Wrapf(err, "Scan %s is %s", x.uuid, status)
+		}
+	}
+
+	return result, errors.Wrapf(ErrWaitTimeout, "Timeout of task id: %s", x.uuid)
+}
+
+// PollEvent is a progress of polling result API notified by WaitOnPoll and Watch.
+type PollEvent struct {
+	// Attempt is a number of requests to result API so far.
+	Attempt int
+	// StatusCode is HTTP status code of the response. It is 0 if no response is received.
+	StatusCode int
+	// Status is status of the scan determined by the response.
+	Status TaskStatus
+	// NextDelay is wait time before next request. It is 0 if polling finishes.
+	NextDelay time.Duration
+	// Elapsed is duration from start of polling.
+	Elapsed time.Duration
+
+	// Final is true for the last event emitted when polling finishes. Err and Result are set only for the last event.
+	Final bool
+	// Err is same with an error returned by Await.
+	Err error
+	// Result is a copy of scan result if the scan is done.
+	Result *ScanResult
+}
+
+// Watch starts polling result API in background and returns a channel of PollEvent. The channel is closed after
+// the final event (Final is true). The caller must receive events until the channel is closed or cancel ctx.
+// The final event is delivered even if ctx is done, but an intermediate event may be dropped in that case.
+// WaitOnPoll in opts is ignored. Task.Result should not be accessed until the channel is closed.
+func (x *Task) Watch(ctx context.Context, opts ...WaitOption) <-chan PollEvent {
+	ch := make(chan PollEvent, 1)
+
+	send := func(ev PollEvent) {
+		select {
+		case ch <- ev:
+			return
+		case <-ctx.Done():
+		}
+		if !ev.Final {
+			return
+		}
+
+		// Deliver the final event even after ctx is done. Drop an unread event to make room in the buffer
+		// not to block when the caller stopped receiving.
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- ev
+	}
+
+	go func() {
+		defer close(ch)
+		x.Await(ctx, append(append([]WaitOption{}, opts...), WaitOnPoll(send))...)
+	}()
+
+	return ch
+}
@@ -0,0 +1,262 @@
+package urlscan_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/urlscan-go/urlscan"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testScanUUID = "0c5ba6ab-1234-4e72-9f4a-4d0d2bd6c0a1"
+
+func TestTaskStatus(t *testing.T) {
+	testCases := []struct {
+		title  string
+		code   int
+		body   string
+		status urlscan.TaskStatus
+	}{
+		{"done", 200, `{"task":{"uuid":"` + testScanUUID + `"}}`, urlscan.TaskStatusDone},
+		{"pending", 404, `{"message":"Scan is not finished yet","status":404}`, urlscan.TaskStatusPending},
+		{"not found", 404, `{"message":"Scan not found","status":404}`, urlscan.TaskStatusNotFound},
+		{"deleted", 410, `{"message":"Scan deleted","status":410}`, urlscan.TaskStatusDeleted},
+		{"failed", 400, `{"message":"Scan failed","status":400}`, urlscan.TaskStatusFailed},
+		{"blocked", 400, `{"message":"Scan prevented","description":"Domain is blocked from scanning","status":400}`, urlscan.TaskStatusBlocked},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			client := newErrorClient(t, tc.code, nil, tc.body)
+			task := client.ResultTask(testScanUUID)
+
+			status, err := task.Status(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tc.status, status)
+			if status == urlscan.TaskStatusDone {
+				assert.Equal(t, testScanUUID, task.Result.Task.UUID)
+			}
+		})
+	}
+}
+
+func TestTaskStatusUnknown(t *testing.T) {
+	client := newErrorClient(t, 503, nil, `Service Unavailable`)
+	task := client.ResultTask(testScanUUID)
+
+	status, err := task.Status(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, urlscan.TaskStatusUnknown, status)
+}
+
+func TestAwaitDone(t *testing.T) {
+	var count int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&count, 1) == 1 {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Scan is not finished yet","status":404}`))
+			return
+		}
+		w.Write([]byte(`{"task":{"uuid":"` + testScanUUID + `"}}`))
+	}))
+
+	task := client.ResultTask(testScanUUID)
+	result, err := task.Await(context.Background(),
+		urlscan.WaitMaxRetry(2),
+		urlscan.WaitBackoff(urlscan.ConstantBackoff{Interval: 10 * time.Millisecond}),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, urlscan.TaskStatusDone, result.Status)
+	assert.Equal(t, 2, result.Attempts)
+	assert.True(t, result.Elapsed > 0)
+	assert.Equal(t, testScanUUID, task.Result.Task.UUID)
+}
+
+func TestAwaitDeleted(t *testing.T) {
+	client := newErrorClient(t, 410, nil, `{"message":"Scan deleted","status":410}`)
+	task := client.ResultTask(testScanUUID)
+
+	result, err := task.Await(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, urlscan.TaskStatusDeleted, result.Status)
+	assert.Equal(t, 1, result.Attempts)
+	assert.Equal(t, 410, errors.Cause(err).(*urlscan.APIError).StatusCode)
+}
+
+func TestAwaitTimeout(t *testing.T) {
+	client := newErrorClient(t, 404, nil, `{"message":"Scan is not finished yet","status":404}`)
+	task := client.ResultTask(testScanUUID)
+
+	result, err := task.Await(context.Background(), urlscan.WaitMaxRetry(1))
+	assert.True(t, errors.Is(err, urlscan.ErrWaitTimeout))
+	assert.Equal(t, urlscan.TaskStatusPending, result.Status)
+	assert.Equal(t, 1, result.Attempts)
+}
+
+func TestAwaitClientError(t *testing.T) {
+	testCases := []struct {
+		title    string
+		code     int
+		attempts int
+	}{
+		{"invalid API key", http.StatusUnauthorized, 1},
+		{"forbidden", http.StatusForbidden, 1},
+		{"unprocessable", http.StatusUnprocessableEntity, 1},
+		{"server error", http.StatusServiceUnavailable, 3},
+		{"rate limited", http.StatusTooManyRequests, 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			client := newErrorClient(t, tc.code, map[string]string{"Retry-After": "0"}, `{"message":"error","status":0}`)
+			task := client.ResultTask(testScanUUID)
+
+			result, err := task.Await(context.Background(), append(fastWait, urlscan.WaitMaxRetry(3))...)
+			require.Error(t, err)
+			assert.Equal(t, urlscan.TaskStatusUnknown, result.Status)
+			assert.Equal(t, tc.attempts, result.Attempts)
+		})
+	}
+}
+
+func TestAwaitClientWaitOptions(t *testing.T) {
+	var count int32
+	base := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"Scan is not finished yet","status":404}`))
+	}))
+	client := urlscan.NewClient("test-api-key",
+		urlscan.WithBaseURL(base.BaseURL),
+		urlscan.WithWaitOptions(
+			urlscan.WaitMaxRetry(3),
+			urlscan.WaitInitialDelay(50*time.Millisecond),
+			urlscan.WaitBackoff(urlscan.ConstantBackoff{Interval: 10 * time.Millisecond}),
+		),
+	)
+
+	task := client.ResultTask(testScanUUID)
+	result, err := task.Await(context.Background())
+	assert.True(t, errors.Is(err, urlscan.ErrWaitTimeout))
+	assert.Equal(t, 3, result.Attempts)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&count))
+	assert.True(t, result.Elapsed >= 70*time.Millisecond)
+
+	// Option of the call overrides one of the client.
+	result, err = task.Await(context.Background(), urlscan.WaitMaxRetry(1))
+	assert.True(t, errors.Is(err, urlscan.ErrWaitTimeout))
+	assert.Equal(t, 1, result.Attempts)
+}
+
+func newPendingThenDoneClient(t *testing.T, pending int32) urlscan.Client {
+	var count int32
+	return newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&count, 1) <= pending {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Scan is not finished yet","status":404}`))
+			return
+		}
+		w.Write([]byte(`{"task":{"uuid":"` + testScanUUID + `"}}`))
+	}))
+}
+
+func TestAwaitOnPoll(t *testing.T) {
+	client := newPendingThenDoneClient(t, 2)
+	task := client.ResultTask(testScanUUID)
+
+	var events []urlscan.PollEvent
+	_, err := task.Await(context.Background(),
+		urlscan.WaitBackoff(urlscan.ConstantBackoff{Interval: 10 * time.Millisecond}),
+		urlscan.WaitOnPoll(func(ev urlscan.PollEvent) {
+			events = append(events, ev)
+		}),
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, 4, len(events))
+	assert.Equal(t, 1, events[0].Attempt)
+	assert.Equal(t, http.StatusNotFound, events[0].StatusCode)
+	assert.Equal(t, urlscan.TaskStatusPending, events[0].Status)
+	assert.Equal(t, 10*time.Millisecond, events[0].NextDelay)
+	assert.False(t, events[0].Final)
+
+	assert.Equal(t, 3, events[2].Attempt)
+	assert.Equal(t, http.StatusOK, events[2].StatusCode)
+	assert.Equal(t, time.Duration(0), events[2].NextDelay)
+
+	assert.True(t, events[3].Final)
+	assert.NoError(t, events[3].Err)
+	require.NotNil(t, events[3].Result)
+	assert.Equal(t, testScanUUID, events[3].Result.Task.UUID)
+}
+
+func TestWatch(t *testing.T) {
+	client := newPendingThenDoneClient(t, 1)
+	task := client.ResultTask(testScanUUID)
+
+	var events []urlscan.PollEvent
+	for ev := range task.Watch(context.Background(), urlscan.WaitBackoff(urlscan.ConstantBackoff{Interval: 10 * time.Millisecond})) {
+		events = append(events, ev)
+	}
+
+	require.Equal(t, 3, len(events))
+	assert.Equal(t, urlscan.TaskStatusPending, events[0].Status)
+	assert.Equal(t, urlscan.TaskStatusDone, events[1].Status)
+	assert.True(t, events[2].Final)
+	require.NotNil(t, events[2].Result)
+	assert.Equal(t, testScanUUID, events[2].Result.Task.UUID)
+}
+
+func TestWatchCanceled(t *testing.T) {
+	client := newPendingThenDoneClient(t, 100)
+	task := client.ResultTask(testScanUUID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := task.Watch(ctx, urlscan.WaitBackoff(urlscan.ConstantBackoff{Interval: time.Hour}))
+
+	ev := <-ch
+	assert.Equal(t, time.Hour, ev.NextDelay)
+	cancel()
+
+	// Final event is delivered and channel is closed after cancel.
+	var final []urlscan.PollEvent
+	for ev := range ch {
+		final = append(final, ev)
+	}
+	require.Equal(t, 1, len(final))
+	assert.True(t, final[0].Final)
+	assert.True(t, errors.Is(final[0].Err, context.Canceled))
+}
+
+func TestWatchCanceledWithoutReceiving(t *testing.T) {
+	client := newPendingThenDoneClient(t, 100)
+	task := client.ResultTask(testScanUUID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := task.Watch(ctx, urlscan.WaitBackoff(urlscan.ConstantBackoff{Interval: 10 * time.Millisecond}))
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+
+	var last urlscan.PollEvent
+	for ev := range ch {
+		last = ev
+	}
+	assert.True(t, last.Final)
+}
+
+func TestWatchDoesNotModifyOptions(t *testing.T) {
+	client := newPendingThenDoneClient(t, 0)
+	task := client.ResultTask(testScanUUID)
+
+	opts := make([]urlscan.WaitOption, 1, 2)
+	opts[0] = urlscan.WaitMaxRetry(3)
+	for range task.Watch(context.Background(), opts...) {
+	}
+	assert.Nil(t, opts[:2][1])
+}
@@ -0,0 +1,239 @@
+package urlscan
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// QueueEntry is a record of a submitted scan saved in Store.
+type QueueEntry struct {
+	UUID string `json:"uuid"`
+	// URL is the submitted URL.
+	URL         string     `json:"url"`
+	ResultURL   string     `json:"result_url,omitempty"`
+	APIURL      string     `json:"api_url,omitempty"`
+	Visibility  Visibility `json:"visibility,omitempty"`
+	SubmittedAt time.Time  `json:"submitted_at"`
+	// Status is TaskStatusPending until the scan is finished.
+	Status      TaskStatus `json:"status"`
+	CompletedAt time.Time  `json:"completed_at"`
+	// Error is a message of the error if the scan was not done successfully.
+	Error string `json:"error,omitempty"`
+}
+
+// Store saves QueueEntry persistently. Implementations must be safe for concurrent use.
+type Store interface {
+	// Put inserts the entry or replaces existing entry with same UUID.
+	Put(entry QueueEntry) error
+	// Load returns all entries in order of first insertion.
+	Load() ([]QueueEntry, error)
+}
+
+// MemoryStore is an in-memory Store mainly for testing.
+type MemoryStore struct {
+	mutex   sync.Mutex
+	entries []QueueEntry
+	index   map[string]int
+}
+
+// NewMemoryStore is a constructor of MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{index: make(map[string]int)}
+}
+
+// Put implements Store.
+func (x *MemoryStore) Put(entry QueueEntry) error {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	if i, ok := x.index[entry.UUID]; ok {
+		x.entries[i] = entry
+		return nil
+	}
+	x.index[entry.UUID] = len(x.entries)
+	x.entries = append(x.entries, entry)
+	return nil
+}
+
+// Load implements Store.
+func (x *MemoryStore) Load() ([]QueueEntry, error) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	entries := make([]QueueEntry, len(x.entries))
+	copy(entries, x.entries)
+	return entries, nil
+}
+
+// FileStore is a Store saving entries into a JSON Lines file. Put appends a line and the latest line of a UUID wins on Load.
+// Load compacts the file by rewriting it with only the latest lines, so the file does not grow without bound.
+// A line broken by crash in the middle of writing can be only the last line, and it is discarded.
+type FileStore struct {
+	mutex sync.Mutex
+	path  string
+}
+
+// NewFileStore is a constructor of FileStore. The file is created by the first Put if it does not exist.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Put implements Store.
+func (x *FileStore) Put(entry QueueEntry) error {
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "Fail to marshal queue entry")
+	}
+
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	fd, err := os.OpenFile(x.path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "Fail to open store file: %s", x.path)
+	}
+	defer fd.Close()
+
+	// Discard a line broken by previous crash not to leave it in the middle of the file.
+	end, err := completeLinesEnd(fd)
+	if err != nil {
+		return errors.Wrapf(err, "Fail to read store file: %s", x.path)
+	}
+	if err := fd.Truncate(end); err != nil {
+		return errors.Wrapf(err, "Fail to truncate store file: %s", x.path)
+	}
+
+	if _, err := fd.WriteAt(append(raw, '\n'), end); err != nil {
+		return errors.Wrapf(err, "Fail to write store file: %s", x.path)
+	}
+	if err := fd.Sync(); err != nil {
+		return errors.Wrapf(err, "Fail to sync store file: %s", x.path)
+	}
+
+	return nil
+}
+
+// completeLinesEnd returns size of fd without the last line that is not terminated by newline.
+func completeLinesEnd(fd *os.File) (int64, error) {
+	stat, err := fd.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 4096)
+	for end := stat.Size(); end > 0; {
+		start := end - int64(len(buf))
+		if start < 0 {
+			start = 0
+		}
+		chunk := buf[:end-start]
+		if _, err := fd.ReadAt(chunk, start); err != nil {
+			return 0, err
+		}
+		if i := bytes.LastIndexByte(chunk, '\n'); i >= 0 {
+			return start + int64(i) + 1, nil
+		}
+		end = start
+	}
+	return 0, nil
+}
+
+// Load implements Store. It returns an error if a line other than the last one is broken.
+func (x *FileStore) Load() ([]QueueEntry, error) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	fd, err := os.Open(x.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "Fail to open store file: %s", x.path)
+	}
+	defer fd.Close()
+
+	var entries []QueueEntry
+	index := make(map[string]int)
+	var lines int
+
+	reader := bufio.NewReader(fd)
+	for lineNo := 1; ; lineNo++ {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, errors.Wrapf(err, "Fail to read store file: %s", x.path)
+		}
+		eof := err == io.EOF
+
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			lines++
+			var entry QueueEntry
+			if err := json.Unmarshal(line, &entry); err != nil || entry.UUID == "" {
+				// Only the last line can be incomplete by crash while writing.
+				if eof {
+					break
+				}
+				return nil, errors.Errorf("Broken line %d in store file: %s", lineNo, x.path)
+			}
+
+			if i, ok := index[entry.UUID]; ok {
+				entries[i] = entry
+			} else {
+				index[entry.UUID] = len(entries)
+				entries = append(entries, entry)
+			}
+		}
+
+		if eof {
+			break
+		}
+	}
+
+	if lines > len(entries) {
+		if err := x.rewrite(entries); err != nil {
+			return nil, err
+		}
+	}
+
+	return entries, nil
+}
+
+// rewrite replaces the file with entries atomically.
+func (x *FileStore) rewrite(entries []QueueEntry) error {
+	tmpPath := x.path + ".tmp"
+	fd, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "Fail to create store file: %s", tmpPath)
+	}
+	defer os.Remove(tmpPath)
+	defer fd.Close()
+
+	w := bufio.NewWriter(fd)
+	for _, entry := range entries {
+		raw, err := json.Marshal(entry)
+		if err != nil {
+			return errors.Wrap(err, "Fail to marshal queue entry")
+		}
+		w.Write(raw)
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		return errors.Wrapf(err, "Fail to write store file: %s", tmpPath)
+	}
+	if err := fd.Sync(); err != nil {
+		return errors.Wrapf(err, "Fail to sync store file: %s", tmpPath)
+	}
+	if err := fd.Close(); err != nil {
+		return errors.Wrapf(err, "Fail to close store file: %s", tmpPath)
+	}
+
+	if err := os.Rename(tmpPath, x.path); err != nil {
+		return errors.Wrapf(err, "Fail to replace store file: %s", x.path)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package urlscan
+
+import (
+	"math"
+	"time"
+)
+
+// DateTime is a time in string returned by urlscan.io, such as "2020-05-08T06:10:36.123Z" or "2006-05-04".
+// The original string is kept to be marshaled as it is.
+type DateTime string
+
+var dateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// Parse parses x in known layouts.
+func (x DateTime) Parse() (time.Time, error) {
+	var err error
+	for _, layout := range dateTimeLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, string(x)); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// Time returns parsed time of x. It returns zero time if x is empty or in unknown layout.
+func (x DateTime) Time() time.Time {
+	t, _ := x.Parse()
+	return t
+}
+
+// UnixTime is seconds from Unix epoch with fraction, e.g. wallTime of request and expires of cookie.
+type UnixTime float64
+
+// Time returns x as time.Time. It returns zero time if x is zero or negative (e.g. -1 for session cookie).
+func (x UnixTime) Time() time.Time {
+	if x <= 0 {
+		return time.Time{}
+	}
+	return fromEpoch(float64(x), time.Second)
+}
+
+// UnixMilliTime is milliseconds from Unix epoch with fraction, e.g. timestamp of console message.
+type UnixMilliTime float64
+
+// Time returns x as time.Time. It returns zero time if x is zero or negative.
+func (x UnixMilliTime) Time() time.Time {
+	if x <= 0 {
+		return time.Time{}
+	}
+	return fromEpoch(float64(x), time.Millisecond)
+}
+
+// fromEpoch converts v units from Unix epoch to time.Time. Fraction is rounded to microsecond because float64 can
+// not keep nanosecond of current time.
+func fromEpoch(v float64, unit time.Duration) time.Time {
+	perUnit := int64(unit / time.Microsecond)
+	whole, frac := math.Modf(v)
+	usec := int64(whole)*perUnit + int64(math.Round(frac*float64(perUnit)))
+	return time.Unix(usec/1e6, (usec%1e6)*1e3)
+}
+
+// ScanTiming is times of page load events.
+type ScanTiming struct {
+	BeginNavigation      DateTime `json:"beginNavigation"`
+	DomContentEventFired DateTime `json:"domContentEventFired"`
+	FrameNavigated       DateTime `json:"frameNavigated"`
+	FrameStartedLoading  DateTime `json:"frameStartedLoading"`
+	FrameStoppedLoading  DateTime `json:"frameStoppedLoading"`
+	LoadEventFired       DateTime `json:"loadEventFired"`
+}
+
+func sinceNavigation(begin, end DateTime) time.Duration {
+	b, e := begin.Time(), end.Time()
+	if b.IsZero() || e.IsZero() || e.Before(b) {
+		return 0
+	}
+	return e.Sub(b)
+}
+
+// Navigation returns duration from begin of navigation to the frame navigated. It returns 0 if unknown.
+func (x ScanTiming) Navigation() time.Duration {
+	return sinceNavigation(x.BeginNavigation, x.FrameNavigated)
+}
+
+// DOMContentLoaded returns duration from begin of navigation to DOMContentLoaded event. It returns 0 if unknown.
+func (x ScanTiming) DOMContentLoaded() time.Duration {
+	return sinceNavigation(x.BeginNavigation, x.DomContentEventFired)
+}
+
+// Load returns duration from begin of navigation to load event. It returns 0 if unknown.
+func (x ScanTiming) Load() time.Duration {
+	return sinceNavigation(x.BeginNavigation, x.LoadEventFired)
+}
+
+// Total returns duration from begin of navigation to the frame stopped loading. It returns 0 if unknown.
+func (x ScanTiming) Total() time.Duration {
+	return sinceNavigation(x.BeginNavigation, x.FrameStoppedLoading)
+}
+
+// ScanResourceTiming is timing of a request. RequestTime is monotonic seconds and others are milliseconds
+// relative to RequestTime, or -1 if not applicable.
+type ScanResourceTiming struct {
+	ConnectEnd        float64 `json:"connectEnd"`
+	ConnectStart      float64 `json:"connectStart"`
+	DNSEnd            float64 `json:"dnsEnd"`
+	DNSStart          float64 `json:"dnsStart"`
+	ProxyEnd          int64   `json:"proxyEnd"`
+	ProxyStart        int64   `json:"proxyStart"`
+	PushEnd           int64   `json:"pushEnd"`
+	PushStart         int64   `json:"pushStart"`
+	ReceiveHeadersEnd float64 `json:"receiveHeadersEnd"`
+	RequestTime       float64 `json:"requestTime"`
+	SendEnd           float64 `json:"sendEnd"`
+	SendStart         float64 `json:"sendStart"`
+	SslEnd            float64 `json:"sslEnd"`
+	SslStart          float64 `json:"sslStart"`
+	WorkerReady       int64   `json:"workerReady"`
+	WorkerStart       int64   `json:"workerStart"`
+}
+
+func millisBetween(start, end float64) time.Duration {
+	if start < 0 || end < 0 || end < start {
+		return 0
+	}
+	return time.Duration((end - start) * float64(time.Millisecond))
+}
+
+// DNS returns duration of DNS lookup. It returns 0 if not applicable.
+func (x ScanResourceTiming) DNS() time.Duration {
+	return millisBetween(x.DNSStart, x.DNSEnd)
+}
+
+// Connect returns duration to establish connection including TLS handshake. It returns 0 if not applicable.
+func (x ScanResourceTiming) Connect() time.Duration {
+	return millisBetween(x.ConnectStart, x.ConnectEnd)
+}
+
+// TLS returns duration of TLS handshake. It returns 0 if not applicable.
+func (x ScanResourceTiming) TLS() time.Duration {
+	return millisBetween(x.SslStart, x.SslEnd)
+}
+
+// TTFB returns duration from end of sending request to receiving response headers. It returns 0 if not applicable.
+func (x ScanResourceTiming) TTFB() time.Duration {
+	return millisBetween(x.SendEnd, x.ReceiveHeadersEnd)
+}
@@ -0,0 +1,85 @@
+package urlscan_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/urlscan-go/urlscan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDateTime(t *testing.T) {
+	assert.Equal(t, time.Date(2020, 5, 8, 6, 10, 36, 123000000, time.UTC), urlscan.DateTime("2020-05-08T06:10:36.123Z").Time().UTC())
+	assert.Equal(t, time.Date(2006, 5, 4, 0, 0, 0, 0, time.UTC), urlscan.DateTime("2006-05-04").Time())
+	assert.True(t, urlscan.DateTime("").Time().IsZero())
+
+	_, err := urlscan.DateTime("yesterday").Parse()
+	assert.Error(t, err)
+}
+
+func TestUnixTime(t *testing.T) {
+	assert.Equal(t, time.Date(2020, 5, 8, 6, 10, 36, 500000000, time.UTC), urlscan.UnixTime(1588918236.5).Time().UTC())
+	assert.True(t, urlscan.UnixTime(-1).Time().IsZero())
+	assert.Equal(t, time.Date(2020, 5, 8, 6, 10, 36, 123000000, time.UTC), urlscan.UnixMilliTime(1588918236123).Time().UTC())
+}
+
+func TestScanResultTimes(t *testing.T) {
+	raw := `{
+		"task": {"time": "2020-05-08T06:10:36.123Z"},
+		"data": {
+			"cookies": [{"name": "a", "expires": 1620454236.25}, {"name": "b", "expires": -1}],
+			"requests": [{
+				"request": {"timestamp": 12345.678, "wallTime": 1588918236.5},
+				"response": {"asn": {"date": "2006-05-04"}, "response": {
+					"securityDetails": {"validFrom": 1588000000, "validTo": 1620000000},
+					"timing": {"requestTime": 12345.6, "dnsStart": 0.5, "dnsEnd": 10.5, "connectStart": 10.5, "connectEnd": 50.5,
+						"sslStart": 20.5, "sslEnd": 50.5, "sendStart": 51, "sendEnd": 52, "receiveHeadersEnd": 152}
+				}}
+			}],
+			"timing": {
+				"beginNavigation": "2020-05-08T06:10:36.000Z",
+				"frameNavigated": "2020-05-08T06:10:36.250Z",
+				"domContentEventFired": "2020-05-08T06:10:36.800Z",
+				"loadEventFired": "2020-05-08T06:10:37.500Z",
+				"frameStoppedLoading": "2020-05-08T06:10:38.000Z"
+			}
+		},
+		"lists": {"certificates": [{"validFrom": 1588000000, "validTo": 1620000000}]}
+	}`
+
+	var result urlscan.ScanResult
+	require.NoError(t, json.Unmarshal([]byte(raw), &result))
+
+	assert.Equal(t, 2020, result.Task.Time.Time().Year())
+	assert.Equal(t, time.Date(2021, 5, 8, 6, 10, 36, 250000000, time.UTC), result.Data.Cookies[0].Expires.Time().UTC())
+	assert.True(t, result.Data.Cookies[1].Expires.Time().IsZero())
+
+	req := result.Data.Requests[0]
+	assert.Equal(t, int64(1588918236), req.Request.WallTime.Time().Unix())
+	assert.Equal(t, 2006, req.Response.Asn.Date.Time().Year())
+	assert.Equal(t, time.Unix(1620000000, 0), req.Response.Response.SecurityDetails.ValidTo.Time())
+	assert.Equal(t, time.Unix(1588000000, 0), result.Lists.Certificates[0].ValidFrom.Time())
+
+	timing := req.Response.Response.Timing
+	assert.Equal(t, 10*time.Millisecond, timing.DNS())
+	assert.Equal(t, 40*time.Millisecond, timing.Connect())
+	assert.Equal(t, 30*time.Millisecond, timing.TLS())
+	assert.Equal(t, 100*time.Millisecond, timing.TTFB())
+
+	assert.Equal(t, 250*time.Millisecond, result.Data.Timing.Navigation())
+	assert.Equal(t, 800*time.Millisecond, result.Data.Timing.DOMContentLoaded())
+	assert.Equal(t, 1500*time.Millisecond, result.Data.Timing.Load())
+	assert.Equal(t, 2*time.Second, result.Data.Timing.Total())
+	assert.Equal(t, time.Duration(0), urlscan.ScanTiming{}.Load())
+
+	// Times are marshaled in original format.
+	encoded, err := json.Marshal(result.Lists.Certificates[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"issuer":"","subjectName":"","validFrom":1588000000,"validTo":1620000000}`, string(encoded))
+}
+
+func TestUnixTimeFarFuture(t *testing.T) {
+	assert.Equal(t, 9999, urlscan.UnixTime(253402300799).Time().UTC().Year())
+}
@@ -0,0 +1,157 @@
+package urlscan
+
+import (
+	"encoding/json"
+)
+
+// ScanVerdicts is verdicts section of ScanResult. Overall is summary of URLScan, Engines and Community.
+type ScanVerdicts struct {
+	Overall   OverallVerdict   `json:"overall"`
+	URLScan   URLScanVerdict   `json:"urlscan"`
+	Engines   EnginesVerdict   `json:"engines"`
+	Community CommunityVerdict `json:"community"`
+}
+
+// OverallVerdict is summary of all verdicts.
+type OverallVerdict struct {
+	Score      int64    `json:"score"`
+	Malicious  bool     `json:"malicious"`
+	Categories []string `json:"categories"`
+	Brands     []Brand  `json:"brands"`
+	Tags       []string `json:"tags"`
+}
+
+// URLScanVerdict is verdict by urlscan.io itself.
+type URLScanVerdict struct {
+	Score      int64    `json:"score"`
+	Malicious  bool     `json:"malicious"`
+	Categories []string `json:"categories"`
+	Brands     []Brand  `json:"brands"`
+	Tags       []string `json:"tags"`
+}
+
+// EnginesVerdict is verdicts by third party engines.
+type EnginesVerdict struct {
+	Score          int64           `json:"score"`
+	Categories     []string        `json:"categories"`
+	EnginesTotal   int64           `json:"enginesTotal"`
+	MaliciousTotal int64           `json:"maliciousTotal"`
+	BenignTotal    int64           `json:"benignTotal"`
+	Malicious      []EngineVerdict `json:"malicious"`
+	Benign         []EngineVerdict `json:"benign"`
+	Verdicts       []EngineVerdict `json:"verdicts"`
+}
+
+// EngineVerdict is a verdict by an engine.
+type EngineVerdict struct {
+	Engine          string   `json:"engine"`
+	Classification  string   `json:"classification"`
+	Categories      []string `json:"categories"`
+	EngineVersion   string   `json:"engineVersion"`
+	RulesVersion    string   `json:"rulesVersion"`
+	DetectionRule   string   `json:"detectionRule"`
+	Description     string   `json:"description"`
+	HasVerdict      bool     `json:"hasVerdict"`
+	ConfidenceLevel float64  `json:"confidenceLevel"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Malicious and Benign lists of engines may be only engine names.
+func (x *EngineVerdict) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*x = EngineVerdict{Engine: name}
+		return nil
+	}
+
+	type engineVerdict EngineVerdict
+	var v engineVerdict
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*x = EngineVerdict(v)
+	return nil
+}
+
+// CommunityVerdict is verdict by votes of urlscan.io users.
+type CommunityVerdict struct {
+	Score          int64    `json:"score"`
+	VotesTotal     int64    `json:"votesTotal"`
+	VotesMalicious int64    `json:"votesMalicious"`
+	VotesBenign    int64    `json:"votesBenign"`
+	Categories     []string `json:"categories"`
+	Tags           []string `json:"tags"`
+}
+
+// Brand is a brand that the page is considered to impersonate.
+type Brand struct {
+	Key      string   `json:"key"`
+	Name     string   `json:"name"`
+	Country  []string `json:"country"`
+	Vertical []string `json:"vertical"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler. A brand may be only a key string.
+func (x *Brand) UnmarshalJSON(data []byte) error {
+	var key string
+	if err := json.Unmarshal(data, &key); err == nil {
+		*x = Brand{Key: key}
+		return nil
+	}
+
+	type brand Brand
+	var v brand
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*x = Brand(v)
+	return nil
+}
+
+// IsMalicious returns true if urlscan.io or any engine judges the page malicious.
+func (x ScanVerdicts) IsMalicious() bool {
+	return x.Overall.Malicious || x.URLScan.Malicious || x.Engines.MaliciousTotal > 0 || len(x.Engines.Malicious) > 0
+}
+
+// Score returns overall score. Positive value means malicious and negative value means benign.
+func (x ScanVerdicts) Score() int64 {
+	return x.Overall.Score
+}
+
+// Brands returns brands detected by overall and urlscan verdicts without duplication.
+func (x ScanVerdicts) Brands() []Brand {
+	var brands []Brand
+	seen := make(map[string]int)
+	for _, list := range [][]Brand{x.Overall.Brands, x.URLScan.Brands} {
+		for _, brand := range list {
+			key := brand.Key
+			if key == "" {
+				key = brand.Name
+			}
+			if i, ok := seen[key]; ok {
+				// Prefer detailed one.
+				if brands[i].Name == "" {
+					brands[i] = brand
+				}
+				continue
+			}
+			seen[key] = len(brands)
+			brands = append(brands, brand)
+		}
+	}
+	return brands
+}
+
+// Categories returns categories of all verdicts without duplication.
+func (x ScanVerdicts) Categories() []string {
+	var categories []string
+	seen := make(map[string]bool)
+	for _, list := range [][]string{x.Overall.Categories, x.URLScan.Categories, x.Engines.Categories, x.Community.Categories} {
+		for _, c := range list {
+			if !seen[c] {
+				seen[c] = true
+				categories = append(categories, c)
+			}
+		}
+	}
+	return categories
+}